@@ -0,0 +1,55 @@
+package colorspace
+
+import (
+	"image/color"
+	"math"
+)
+
+// APCA 0.0.98G-4g constants, see https://github.com/Myndex/apca-w3
+const (
+	apcaNormBG, apcaNormText       = 0.56, 0.57
+	apcaReverseText, apcaReverseBG = 0.62, 0.65
+	apcaBlackThreshold             = 0.022
+	apcaBlackClamp                 = 1.414
+	apcaScale                      = 1.14
+	apcaLowOffset                  = 0.027
+	apcaLowClip                    = 0.1
+	apcaDeltaYMin                  = 0.0005
+)
+
+// APCAContrast returns the APCA lightness contrast Lc of text on a background,
+// from about 106 for black on white to about -108 for white on black. It is
+// positive for dark text on a light background and negative the other way.
+func APCAContrast(text, background color.Color) float64 {
+	textY, bgY := apcaLuminance(text), apcaLuminance(background)
+	if math.Abs(bgY-textY) < apcaDeltaYMin {
+		return 0
+	}
+
+	if bgY > textY {
+		sapc := (math.Pow(bgY, apcaNormBG) - math.Pow(textY, apcaNormText)) * apcaScale
+		if sapc < apcaLowClip {
+			return 0
+		}
+		return (sapc - apcaLowOffset) * 100
+	}
+
+	sapc := (math.Pow(bgY, apcaReverseBG) - math.Pow(textY, apcaReverseText)) * apcaScale
+	if sapc > -apcaLowClip {
+		return 0
+	}
+	return (sapc + apcaLowOffset) * 100
+}
+
+// apcaLuminance is the screen luminance APCA uses, a plain 2.4 power curve
+// with a soft clamp near black
+func apcaLuminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	y := 0.2126729*math.Pow(float64(r>>8)/255, 2.4) +
+		0.7151522*math.Pow(float64(g>>8)/255, 2.4) +
+		0.0721750*math.Pow(float64(b>>8)/255, 2.4)
+	if y > apcaBlackThreshold {
+		return y
+	}
+	return y + math.Pow(apcaBlackThreshold-y, apcaBlackClamp)
+}
@@ -0,0 +1,27 @@
+package colorspace
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_APCAContrast(t *testing.T) {
+	// reference values from the APCA 0.0.98G-4g test suite
+	tests := []struct {
+		text, background color.RGBA
+		expected         float64
+	}{
+		{color.RGBA{0x88, 0x88, 0x88, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}, 63.056},
+		{color.RGBA{0xff, 0xff, 0xff, 0xff}, color.RGBA{0x88, 0x88, 0x88, 0xff}, -68.541},
+		{color.RGBA{0x00, 0x00, 0x00, 0xff}, color.RGBA{0xaa, 0xaa, 0xaa, 0xff}, 58.146},
+		{color.RGBA{0xaa, 0xaa, 0xaa, 0xff}, color.RGBA{0x00, 0x00, 0x00, 0xff}, -56.241},
+		{color.RGBA{0x12, 0x34, 0x56, 0xff}, color.RGBA{0x12, 0x34, 0x56, 0xff}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := APCAContrast(tt.text, tt.background); math.Abs(got-tt.expected) > 0.01 {
+			t.Errorf("Expected Lc %v for %v on %v, got %.3f", tt.expected, tt.text, tt.background, got)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package colorspace
+
+import (
+	"image/color"
+	"math"
+)
+
+// OKLab is a color in the OKLab perceptual color space.
+// L is lightness in [0, 1], A and B are the green-red and blue-yellow axes.
+type OKLab struct {
+	L, A, B float64
+}
+
+// OKLCh is the cylindrical form of OKLab.
+// C is chroma and H is the hue angle in degrees in [0, 360).
+type OKLCh struct {
+	L, C, H float64
+}
+
+// achromaticChroma is the chroma below which a color is treated as a gray
+// and its hue is considered meaningless.
+const achromaticChroma = 0.0001
+
+// SRGBToLinear converts a gamma encoded sRGB channel in [0, 1] to linear light.
+func SRGBToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// LinearToSRGB converts a linear light channel in [0, 1] to gamma encoded sRGB.
+func LinearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// LinearRGB returns the linear light red, green and blue channels of c in [0, 1].
+// Alpha is ignored.
+func LinearRGB(c color.Color) (r, g, b float64) {
+	cr, cg, cb, _ := c.RGBA()
+	return SRGBToLinear(float64(cr>>8) / 255),
+		SRGBToLinear(float64(cg>>8) / 255),
+		SRGBToLinear(float64(cb>>8) / 255)
+}
+
+// FromLinearRGB builds an opaque color from linear light channels,
+// clipping each channel to [0, 1].
+func FromLinearRGB(r, g, b float64) color.RGBA {
+	return color.RGBA{
+		R: channelToByte(LinearToSRGB(clamp01(r))),
+		G: channelToByte(LinearToSRGB(clamp01(g))),
+		B: channelToByte(LinearToSRGB(clamp01(b))),
+		A: 255,
+	}
+}
+
+// ToOKLab converts a color to OKLab.
+func ToOKLab(c color.Color) OKLab {
+	r, g, b := LinearRGB(c)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return OKLab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// ToOKLCh converts a color to OKLCh.
+func ToOKLCh(c color.Color) OKLCh {
+	return ToOKLab(c).LCh()
+}
+
+// linear returns the linear sRGB channels of an OKLab color, which may fall
+// outside of [0, 1] when the color is out of gamut.
+func (c OKLab) linear() (r, g, b float64) {
+	l := c.L + 0.3963377774*c.A + 0.2158037573*c.B
+	m := c.L - 0.1055613458*c.A - 0.0638541728*c.B
+	s := c.L - 0.0894841775*c.A - 1.2914855480*c.B
+
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return 4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+}
+
+// InGamut reports whether the color can be represented in sRGB without clipping.
+func (c OKLab) InGamut() bool {
+	const eps = 1e-6
+	r, g, b := c.linear()
+	return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+}
+
+// ToRGBA converts the color to 8-bit sRGB, clipping out of gamut channels.
+func (c OKLab) ToRGBA() color.RGBA {
+	return FromLinearRGB(c.linear())
+}
+
+// LCh converts the color to its cylindrical form.
+func (c OKLab) LCh() OKLCh {
+	chroma := math.Hypot(c.A, c.B)
+	if chroma < achromaticChroma {
+		return OKLCh{L: c.L, C: chroma, H: 0}
+	}
+	return OKLCh{L: c.L, C: chroma, H: NormalizeHue(math.Atan2(c.B, c.A) * 180 / math.Pi)}
+}
+
+// Lab converts the color back to rectangular OKLab.
+func (c OKLCh) Lab() OKLab {
+	h := c.H * math.Pi / 180
+	return OKLab{L: c.L, A: c.C * math.Cos(h), B: c.C * math.Sin(h)}
+}
+
+// IsAchromatic reports whether the color is close enough to gray that its
+// hue carries no information.
+func (c OKLCh) IsAchromatic() bool {
+	return c.C < achromaticChroma*20
+}
+
+// ToRGBA converts the color to 8-bit sRGB. Out of gamut colors are mapped
+// into gamut by reducing chroma while keeping lightness and hue.
+func (c OKLCh) ToRGBA() color.RGBA {
+	c.L = clamp01(c.L)
+	if c.C < 0 {
+		c.C = 0
+	}
+	if c.Lab().InGamut() {
+		return c.Lab().ToRGBA()
+	}
+
+	lo, hi := 0.0, c.C
+	for i := 0; i < 24; i++ {
+		mid := (lo + hi) / 2
+		if (OKLCh{L: c.L, C: mid, H: c.H}).Lab().InGamut() {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return OKLCh{L: c.L, C: lo, H: c.H}.Lab().ToRGBA()
+}
+
+// DeltaEOK returns the Euclidean distance between two colors in OKLab.
+func DeltaEOK(a, b OKLab) float64 {
+	dl, da, db := a.L-b.L, a.A-b.A, a.B-b.B
+	return math.Sqrt(dl*dl + da*da + db*db)
+}
+
+// NormalizeHue wraps a hue angle in degrees into [0, 360).
+func NormalizeHue(h float64) float64 {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func channelToByte(v float64) uint8 {
+	return uint8(math.Round(clamp01(v) * 255))
+}
+
+// HSL is a color in the HSL model, H in degrees [0, 360), S and L in [0, 1].
+type HSL struct {
+	H, S, L float64
+}
+
+// ToHSL converts a color to HSL. Alpha is ignored.
+func ToHSL(c color.Color) HSL {
+	cr, cg, cb, _ := c.RGBA()
+	r, g, b := float64(cr>>8)/255, float64(cg>>8)/255, float64(cb>>8)/255
+
+	maxC := math.Max(r, math.Max(g, b))
+	minC := math.Min(r, math.Min(g, b))
+	l := (maxC + minC) / 2
+	delta := maxC - minC
+	if delta == 0 {
+		return HSL{H: 0, S: 0, L: l}
+	}
+
+	s := delta / (1 - math.Abs(2*l-1))
+
+	var h float64
+	switch maxC {
+	case r:
+		h = math.Mod((g-b)/delta, 6)
+	case g:
+		h = (b-r)/delta + 2
+	default:
+		h = (r-g)/delta + 4
+	}
+
+	return HSL{H: NormalizeHue(h * 60), S: s, L: l}
+}
+
+// RelativeLuminance returns the WCAG 2.x relative luminance of a color in [0, 1].
+func RelativeLuminance(c color.Color) float64 {
+	r, g, b := LinearRGB(c)
+	return 0.2126*r + 0.7152*g + 0.0722*b
+}
+
+// ContrastRatio returns the WCAG 2.x contrast ratio of two colors, from 1 for
+// equal luminance to 21 for black on white.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := RelativeLuminance(a), RelativeLuminance(b)
+	return (math.Max(la, lb) + 0.05) / (math.Min(la, lb) + 0.05)
+}
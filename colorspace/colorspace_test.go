@@ -0,0 +1,129 @@
+package colorspace
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_OKLabRoundTrip(t *testing.T) {
+	colors := []color.RGBA{
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{234, 118, 203, 255},
+		{30, 102, 245, 255},
+		{64, 160, 43, 255},
+	}
+
+	for _, c := range colors {
+		if got := ToOKLab(c).ToRGBA(); got != c {
+			t.Errorf("Expected OKLab round trip of %v, got %v", c, got)
+		}
+		if got := ToOKLCh(c).ToRGBA(); got != c {
+			t.Errorf("Expected OKLCh round trip of %v, got %v", c, got)
+		}
+	}
+}
+
+func Test_ToOKLab(t *testing.T) {
+	white := ToOKLab(color.RGBA{255, 255, 255, 255})
+	if math.Abs(white.L-1) > 1e-3 || math.Abs(white.A) > 1e-3 || math.Abs(white.B) > 1e-3 {
+		t.Errorf("Expected white to be L=1 a=0 b=0, got %+v", white)
+	}
+
+	// reference value from https://bottosson.github.io/posts/oklab/
+	red := ToOKLab(color.RGBA{255, 0, 0, 255})
+	if math.Abs(red.L-0.628) > 1e-3 || math.Abs(red.A-0.225) > 1e-3 || math.Abs(red.B-0.126) > 1e-3 {
+		t.Errorf("Expected red to be L=0.628 a=0.225 b=0.126, got %+v", red)
+	}
+}
+
+func Test_OKLChGamutMapping(t *testing.T) {
+	outOfGamut := OKLCh{L: 0.7, C: 0.5, H: 150}
+	if outOfGamut.Lab().InGamut() {
+		t.Fatalf("Expected %+v to be out of gamut", outOfGamut)
+	}
+
+	mapped := ToOKLCh(outOfGamut.ToRGBA())
+	if math.Abs(mapped.L-0.7) > 0.01 {
+		t.Errorf("Expected gamut mapping to keep lightness 0.7, got %v", mapped.L)
+	}
+	if math.Abs(mapped.H-150) > 2 {
+		t.Errorf("Expected gamut mapping to keep hue 150, got %v", mapped.H)
+	}
+}
+
+func Test_NormalizeHue(t *testing.T) {
+	tests := []struct {
+		input, expected float64
+	}{
+		{0, 0},
+		{360, 0},
+		{-30, 330},
+		{725, 5},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeHue(tt.input); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("Expected %v for input %v, got %v", tt.expected, tt.input, got)
+		}
+	}
+}
+
+func Test_ToHSL(t *testing.T) {
+	tests := []struct {
+		input    color.RGBA
+		expected HSL
+	}{
+		{color.RGBA{0, 0, 0, 255}, HSL{0, 0, 0}},
+		{color.RGBA{255, 255, 255, 255}, HSL{0, 0, 1}},
+		{color.RGBA{255, 0, 0, 255}, HSL{0, 1, 0.5}},
+		{color.RGBA{0, 0, 255, 255}, HSL{240, 1, 0.5}},
+		{color.RGBA{234, 118, 203, 255}, HSL{316.03, 0.7342, 0.6902}},
+	}
+
+	for _, tt := range tests {
+		got := ToHSL(tt.input)
+		if math.Abs(got.H-tt.expected.H) > 0.01 ||
+			math.Abs(got.S-tt.expected.S) > 0.001 ||
+			math.Abs(got.L-tt.expected.L) > 0.001 {
+			t.Errorf("Expected %+v for input %v, got %+v", tt.expected, tt.input, got)
+		}
+	}
+}
+
+func Test_RelativeLuminance(t *testing.T) {
+	tests := []struct {
+		input    color.RGBA
+		expected float64
+	}{
+		{color.RGBA{0, 0, 0, 255}, 0},
+		{color.RGBA{255, 255, 255, 255}, 1},
+		{color.RGBA{255, 0, 0, 255}, 0.2126},
+		{color.RGBA{128, 128, 128, 255}, 0.2159},
+	}
+
+	for _, tt := range tests {
+		if got := RelativeLuminance(tt.input); math.Abs(got-tt.expected) > 1e-4 {
+			t.Errorf("Expected %v for input %v, got %v", tt.expected, tt.input, got)
+		}
+	}
+}
+
+func Test_ContrastRatio(t *testing.T) {
+	tests := []struct {
+		a, b     color.RGBA
+		expected float64
+	}{
+		{color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}, 21},
+		{color.RGBA{255, 255, 255, 255}, color.RGBA{0, 0, 0, 255}, 21},
+		{color.RGBA{0x77, 0x77, 0x77, 255}, color.RGBA{255, 255, 255, 255}, 4.4781},
+		{color.RGBA{0x12, 0x34, 0x56, 255}, color.RGBA{0x12, 0x34, 0x56, 255}, 1},
+	}
+
+	for _, tt := range tests {
+		if got := ContrastRatio(tt.a, tt.b); math.Abs(got-tt.expected) > 1e-3 {
+			t.Errorf("Expected contrast %v for %v and %v, got %v", tt.expected, tt.a, tt.b, got)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package colorspace
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Deficiency is a kind of dichromatic color vision deficiency.
+type Deficiency string
+
+const (
+	// Protanopia is the lack of the long wavelength (red) cones
+	Protanopia Deficiency = "protanopia"
+	// Deuteranopia is the lack of the medium wavelength (green) cones
+	Deuteranopia Deficiency = "deuteranopia"
+	// Tritanopia is the lack of the short wavelength (blue) cones
+	Tritanopia Deficiency = "tritanopia"
+)
+
+// CVDMethod is the model a color vision deficiency is simulated with.
+type CVDMethod string
+
+const (
+	// CVDMachado is the model of Machado, Oliveira and Fernandes (2009)
+	CVDMachado CVDMethod = "machado"
+	// CVDBrettel is the model of Brettel, Viénot and Mollon (1997), which is
+	// more accurate for tritanopia
+	CVDBrettel CVDMethod = "brettel"
+)
+
+// ParseDeficiency validates a color vision deficiency name given on the command line.
+func ParseDeficiency(name string) (Deficiency, error) {
+	switch deficiency := Deficiency(name); deficiency {
+	case Protanopia, Deuteranopia, Tritanopia:
+		return deficiency, nil
+	default:
+		return "", fmt.Errorf("invalid color vision deficiency '%v' (expected protanopia, deuteranopia or tritanopia)", name)
+	}
+}
+
+// ParseCVDMethod validates a simulation method name given on the command line.
+func ParseCVDMethod(name string) (CVDMethod, error) {
+	switch method := CVDMethod(name); method {
+	case CVDMachado, CVDBrettel:
+		return method, nil
+	default:
+		return "", fmt.Errorf("invalid simulation method '%v' (expected machado or brettel)", name)
+	}
+}
+
+type matrix3 [9]float64
+
+func (m matrix3) apply(r, g, b float64) (float64, float64, float64) {
+	return m[0]*r + m[1]*g + m[2]*b,
+		m[3]*r + m[4]*g + m[5]*b,
+		m[6]*r + m[7]*g + m[8]*b
+}
+
+// machadoMatrices are the linear sRGB matrices of Machado et al. at severity 1
+var machadoMatrices = map[Deficiency]matrix3{
+	Protanopia: {
+		0.152286, 1.052583, -0.204868,
+		0.114503, 0.786281, 0.099216,
+		-0.003882, -0.048116, 1.051998,
+	},
+	Deuteranopia: {
+		0.367322, 0.860646, -0.227968,
+		0.280085, 0.672501, 0.047413,
+		-0.011820, 0.042940, 0.968881,
+	},
+	Tritanopia: {
+		1.255528, -0.076749, -0.178779,
+		-0.078411, 0.930809, 0.147602,
+		0.004733, 0.691367, 0.303900,
+	},
+}
+
+// brettelParams project linear sRGB onto the two half-planes of Brettel's
+// model, chosen by the side of the separation plane a color lies on. The
+// values are those of DaltonLens for the Smith and Pokorny cone fundamentals.
+type brettelParams struct {
+	first, second matrix3
+	normal        [3]float64
+}
+
+var brettelMatrices = map[Deficiency]brettelParams{
+	Protanopia: {
+		first: matrix3{
+			0.14980, 1.19548, -0.34528,
+			0.10764, 0.84864, 0.04372,
+			0.00384, -0.00540, 1.00156,
+		},
+		second: matrix3{
+			0.14570, 1.16172, -0.30742,
+			0.10816, 0.85291, 0.03892,
+			0.00386, -0.00524, 1.00139,
+		},
+		normal: [3]float64{0.00048, 0.00393, -0.00441},
+	},
+	Deuteranopia: {
+		first: matrix3{
+			0.36477, 0.86381, -0.22858,
+			0.26294, 0.64245, 0.09462,
+			-0.02006, 0.02728, 0.99278,
+		},
+		second: matrix3{
+			0.37298, 0.88166, -0.25464,
+			0.25954, 0.63506, 0.10540,
+			-0.01980, 0.02784, 0.99196,
+		},
+		normal: [3]float64{-0.00281, -0.00611, 0.00892},
+	},
+	Tritanopia: {
+		first: matrix3{
+			1.01277, 0.13548, -0.14826,
+			-0.01243, 0.86812, 0.14431,
+			0.07589, 0.80500, 0.11911,
+		},
+		second: matrix3{
+			0.93678, 0.18979, -0.12657,
+			0.06154, 0.81526, 0.12320,
+			-0.37562, 1.12767, 0.24796,
+		},
+		normal: [3]float64{0.03901, -0.02788, -0.01113},
+	},
+}
+
+// CVDSimulation simulates how a color looks to people with a color vision
+// deficiency. Severity goes from 0 (normal vision) to 1 (dichromacy), values
+// in between blend the simulated color with the original.
+type CVDSimulation struct {
+	Deficiency Deficiency
+	Method     CVDMethod
+	Severity   float64
+}
+
+// Simulate returns the color as seen with the deficiency. Alpha is ignored.
+func (s CVDSimulation) Simulate(c color.Color) color.RGBA {
+	return FromLinearRGB(s.simulate(LinearRGB(c)))
+}
+
+// simulate blends the linear sRGB color as seen with dichromacy with the
+// color itself by the severity
+func (s CVDSimulation) simulate(r, g, b float64) (float64, float64, float64) {
+	sr, sg, sb := s.dichromat(r, g, b)
+	severity := clamp01(s.Severity)
+	return lerp(r, sr, severity), lerp(g, sg, severity), lerp(b, sb, severity)
+}
+
+func (s CVDSimulation) dichromat(r, g, b float64) (float64, float64, float64) {
+	if s.Method == CVDBrettel {
+		params := brettelMatrices[s.Deficiency]
+		if r*params.normal[0]+g*params.normal[1]+b*params.normal[2] >= 0 {
+			return params.first.apply(r, g, b)
+		}
+		return params.second.apply(r, g, b)
+	}
+	return machadoMatrices[s.Deficiency].apply(r, g, b)
+}
+
+// daltonizeMatrices shift the part of a color lost to a deficiency into the
+// channels that are still seen, after Fidaner, Lin and Ozguven (2005)
+var daltonizeMatrices = map[Deficiency]matrix3{
+	Protanopia: {
+		0, 0, 0,
+		0.7, 1, 0,
+		0.7, 0, 1,
+	},
+	Deuteranopia: {
+		0, 0, 0,
+		0.7, 1, 0,
+		0.7, 0, 1,
+	},
+	Tritanopia: {
+		1, 0, 0.7,
+		0, 1, 0.7,
+		0, 0, 0,
+	},
+}
+
+// Daltonize returns the color corrected so that more of the difference between
+// colors lost to the deficiency remains visible with it. Grays are kept.
+func (s CVDSimulation) Daltonize(c color.Color) color.RGBA {
+	r, g, b := LinearRGB(c)
+	sr, sg, sb := s.simulate(r, g, b)
+	dr, dg, db := daltonizeMatrices[s.Deficiency].apply(r-sr, g-sg, b-sb)
+	return FromLinearRGB(r+dr, g+dg, b+db)
+}
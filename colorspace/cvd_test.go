@@ -0,0 +1,97 @@
+package colorspace
+
+import (
+	"image/color"
+	"testing"
+)
+
+func Test_ParseDeficiency(t *testing.T) {
+	for _, name := range []string{"protanopia", "deuteranopia", "tritanopia"} {
+		if deficiency, err := ParseDeficiency(name); err != nil || string(deficiency) != name {
+			t.Errorf("Expected deficiency '%v', got '%v' (%v)", name, deficiency, err)
+		}
+	}
+	if _, err := ParseDeficiency("achromatopsia"); err == nil {
+		t.Errorf("Expected an error for an unknown deficiency")
+	}
+	if _, err := ParseCVDMethod("vienot"); err == nil {
+		t.Errorf("Expected an error for an unknown simulation method")
+	}
+}
+
+func Test_CVDSimulation(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	green := color.RGBA{0x00, 0x80, 0x00, 0xff}
+	blue := color.RGBA{0x00, 0x00, 0xff, 0xff}
+	teal := color.RGBA{0x00, 0x80, 0x80, 0xff}
+
+	for _, method := range []CVDMethod{CVDMachado, CVDBrettel} {
+		for _, deficiency := range []Deficiency{Protanopia, Deuteranopia, Tritanopia} {
+			sim := CVDSimulation{Deficiency: deficiency, Method: method, Severity: 1}
+			for _, gray := range []color.RGBA{{0, 0, 0, 0xff}, {0x80, 0x80, 0x80, 0xff}, {0xff, 0xff, 0xff, 0xff}} {
+				if got := sim.Simulate(gray); !closeRGBA(got, gray, 2) {
+					t.Errorf("Expected %v %v to keep gray %v, got %v", method, deficiency, gray, got)
+				}
+			}
+
+			sim.Severity = 0
+			if got := sim.Simulate(red); got != red {
+				t.Errorf("Expected severity 0 to keep %v, got %v", red, got)
+			}
+		}
+
+		// red and green look alike without red or green cones, blue and teal
+		// without blue cones
+		confusions := []struct {
+			deficiency Deficiency
+			a, b       color.RGBA
+		}{
+			{Protanopia, red, green},
+			{Deuteranopia, red, green},
+			{Tritanopia, blue, teal},
+		}
+		for _, tt := range confusions {
+			sim := CVDSimulation{Deficiency: tt.deficiency, Method: method, Severity: 1}
+			before := DeltaEOK(ToOKLab(tt.a), ToOKLab(tt.b))
+			after := DeltaEOK(ToOKLab(sim.Simulate(tt.a)), ToOKLab(sim.Simulate(tt.b)))
+			if after > before/2 {
+				t.Errorf("Expected %v %v to bring %v and %v closer than %.3f, got %.3f",
+					method, tt.deficiency, tt.a, tt.b, before/2, after)
+			}
+		}
+	}
+}
+
+func Test_Daltonize(t *testing.T) {
+	tests := []struct {
+		deficiency Deficiency
+		a, b       color.RGBA
+	}{
+		{Protanopia, color.RGBA{0xd0, 0x30, 0x30, 0xff}, color.RGBA{0x40, 0x90, 0x30, 0xff}},
+		{Deuteranopia, color.RGBA{0xd0, 0x30, 0x30, 0xff}, color.RGBA{0x40, 0x90, 0x30, 0xff}},
+		{Tritanopia, color.RGBA{0x20, 0x40, 0xd0, 0xff}, color.RGBA{0x20, 0x90, 0x90, 0xff}},
+	}
+
+	for _, tt := range tests {
+		sim := CVDSimulation{Deficiency: tt.deficiency, Method: CVDMachado, Severity: 1}
+		gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+		if got := sim.Daltonize(gray); !closeRGBA(got, gray, 2) {
+			t.Errorf("Expected %v daltonization to keep gray, got %v", tt.deficiency, got)
+		}
+
+		before := DeltaEOK(ToOKLab(sim.Simulate(tt.a)), ToOKLab(sim.Simulate(tt.b)))
+		after := DeltaEOK(ToOKLab(sim.Simulate(sim.Daltonize(tt.a))), ToOKLab(sim.Simulate(sim.Daltonize(tt.b))))
+		if after <= before {
+			t.Errorf("Expected %v daltonization to set %v and %v further apart than %.3f, got %.3f",
+				tt.deficiency, tt.a, tt.b, before, after)
+		}
+	}
+}
+
+func closeRGBA(a, b color.RGBA, tolerance int) bool {
+	diff := func(x, y uint8) bool {
+		d := int(x) - int(y)
+		return d <= tolerance && d >= -tolerance
+	}
+	return diff(a.R, b.R) && diff(a.G, b.G) && diff(a.B, b.B) && a.A == b.A
+}
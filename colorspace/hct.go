@@ -0,0 +1,215 @@
+package colorspace
+
+import (
+	"image/color"
+	"math"
+)
+
+// HCT is a color in Google's HCT color space, as used by Material Design.
+// Hue and Chroma are those of CAM16 in degrees and unbounded chroma units,
+// Tone is the CIE L* lightness in [0, 100].
+type HCT struct {
+	Hue, Chroma, Tone float64
+}
+
+// hctSearchSteps bounds the binary searches that solve a HCT color
+const hctSearchSteps = 40
+
+// cam16Conditions are the CAM16 viewing conditions of HCT: a D65 white point,
+// an adapting luminance of 200/pi times the luminance of L* 50, an L* 50
+// background and an average surround.
+var cam16Conditions = newCAM16Conditions()
+
+type cam16ViewingConditions struct {
+	n, aw, nbb, ncb, c, nc, fl, z float64
+	rgbD                          [3]float64
+}
+
+func newCAM16Conditions() cam16ViewingConditions {
+	whitePoint := [3]float64{95.047, 100.0, 108.883}
+	adaptingLuminance := 200 / math.Pi * YFromLstar(50) / 100
+	backgroundLstar := 50.0
+	surround := 2.0
+
+	rW, gW, bW := cam16Cone(whitePoint[0], whitePoint[1], whitePoint[2])
+	f := 0.8 + surround/10
+	c := 0.59 + (0.69-0.59)*(f-0.9)*10
+	d := clamp01(f * (1 - (1/3.6)*math.Exp((-adaptingLuminance-42)/92)))
+	rgbD := [3]float64{d*(100/rW) + 1 - d, d*(100/gW) + 1 - d, d*(100/bW) + 1 - d}
+
+	k := 1 / (5*adaptingLuminance + 1)
+	k4 := k * k * k * k
+	k4F := 1 - k4
+	fl := k4*adaptingLuminance + 0.1*k4F*k4F*math.Cbrt(5*adaptingLuminance)
+	n := YFromLstar(backgroundLstar) / whitePoint[1]
+	z := 1.48 + math.Sqrt(n)
+	nbb := 0.725 / math.Pow(n, 0.2)
+
+	adapted := func(channel, white float64) float64 {
+		factor := math.Pow(fl*channel*white/100, 0.42)
+		return 400 * factor / (factor + 27.13)
+	}
+	rA, gA, bA := adapted(rgbD[0], rW), adapted(rgbD[1], gW), adapted(rgbD[2], bW)
+	aw := (2*rA + gA + 0.05*bA) * nbb
+
+	return cam16ViewingConditions{
+		n: n, aw: aw, nbb: nbb, ncb: nbb, c: c, nc: f, fl: fl, z: z, rgbD: rgbD,
+	}
+}
+
+// cam16Cone converts XYZ to the CAM16 cone responses
+func cam16Cone(x, y, z float64) (r, g, b float64) {
+	return 0.401288*x + 0.650173*y - 0.051461*z,
+		-0.250268*x + 1.204414*y + 0.045854*z,
+		-0.002079*x + 0.048952*y + 0.953127*z
+}
+
+// ToHCT converts a color to HCT.
+func ToHCT(c color.Color) HCT {
+	r, g, b := LinearRGB(c)
+	x := (0.41233895*r + 0.35762064*g + 0.18051042*b) * 100
+	y := (0.2126*r + 0.7152*g + 0.0722*b) * 100
+	z := (0.01932141*r + 0.11916382*g + 0.95034478*b) * 100
+
+	hue, chroma := cam16HueChroma(x, y, z)
+	return HCT{Hue: hue, Chroma: chroma, Tone: LstarFromY(y)}
+}
+
+// cam16HueChroma returns the CAM16 hue and chroma of an XYZ color
+func cam16HueChroma(x, y, z float64) (hue, chroma float64) {
+	vc := cam16Conditions
+	rC, gC, bC := cam16Cone(x, y, z)
+
+	adapted := func(channel, d float64) float64 {
+		af := math.Pow(vc.fl*math.Abs(d*channel)/100, 0.42)
+		return math.Copysign(400*af/(af+27.13), d*channel)
+	}
+	rA, gA, bA := adapted(rC, vc.rgbD[0]), adapted(gC, vc.rgbD[1]), adapted(bC, vc.rgbD[2])
+
+	a := (11*rA - 12*gA + bA) / 11
+	b := (rA + gA - 2*bA) / 9
+	u := (20*rA + 20*gA + 21*bA) / 20
+	p2 := (40*rA + 20*gA + bA) / 20
+
+	hue = NormalizeHue(math.Atan2(b, a) * 180 / math.Pi)
+	j := 100 * math.Pow(p2*vc.nbb/vc.aw, vc.c*vc.z)
+
+	huePrime := hue
+	if huePrime < 20.14 {
+		huePrime += 360
+	}
+	eHue := 0.25 * (math.Cos(huePrime*math.Pi/180+2) + 3.8)
+	p1 := 50000.0 / 13 * eHue * vc.nc * vc.ncb
+	t := p1 * math.Hypot(a, b) / (u + 0.305)
+	alpha := math.Pow(t, 0.9) * math.Pow(1.64-math.Pow(0.29, vc.n), 0.73)
+
+	return hue, alpha * math.Sqrt(j/100)
+}
+
+// cam16ToLinear converts a CAM16 lightness J, chroma and hue to linear sRGB,
+// which may fall outside of [0, 1]
+func cam16ToLinear(j, chroma, hue float64) (r, g, b float64) {
+	vc := cam16Conditions
+	alpha := 0.0
+	if chroma != 0 && j != 0 {
+		alpha = chroma / math.Sqrt(j/100)
+	}
+	t := math.Pow(alpha/math.Pow(1.64-math.Pow(0.29, vc.n), 0.73), 1/0.9)
+	hRad := hue * math.Pi / 180
+
+	eHue := 0.25 * (math.Cos(hRad+2) + 3.8)
+	ac := vc.aw * math.Pow(j/100, 1/vc.c/vc.z)
+	p1 := eHue * (50000.0 / 13) * vc.nc * vc.ncb
+	p2 := ac / vc.nbb
+
+	hSin, hCos := math.Sin(hRad), math.Cos(hRad)
+	gamma := 23 * (p2 + 0.305) * t / (23*p1 + 11*t*hCos + 108*t*hSin)
+	a, b2 := gamma*hCos, gamma*hSin
+
+	rA := (460*p2 + 451*a + 288*b2) / 1403
+	gA := (460*p2 - 891*a - 261*b2) / 1403
+	bA := (460*p2 - 220*a - 6300*b2) / 1403
+
+	unadapted := func(channel, d float64) float64 {
+		base := math.Max(0, 27.13*math.Abs(channel)/(400-math.Abs(channel)))
+		return math.Copysign(100/vc.fl*math.Pow(base, 1/0.42), channel) / d
+	}
+	rF, gF, bF := unadapted(rA, vc.rgbD[0]), unadapted(gA, vc.rgbD[1]), unadapted(bA, vc.rgbD[2])
+
+	x := 1.86206786*rF - 1.01125463*gF + 0.14918677*bF
+	y := 0.38752654*rF + 0.62144744*gF - 0.00897398*bF
+	z := -0.01584150*rF - 0.03412294*gF + 1.04996444*bF
+
+	return (3.2413774792388685*x - 1.5376652402851851*y - 0.49885366846268053*z) / 100,
+		(-0.9691452513005321*x + 1.8758853451067872*y + 0.04156585616912061*z) / 100,
+		(0.05562093689691305*x - 0.20395524564742123*y + 1.0571799111220335*z) / 100
+}
+
+// ToRGBA converts the color to 8-bit sRGB. The tone and hue are kept, colors
+// with more chroma than sRGB can show at that tone get the most it can.
+func (c HCT) ToRGBA() color.RGBA {
+	if c.Tone <= 0 {
+		return color.RGBA{A: 255}
+	}
+	if c.Tone >= 100 {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+
+	y := YFromLstar(c.Tone) / 100
+	if r, g, b, ok := solveHCT(c.Hue, math.Max(0, c.Chroma), y); ok {
+		return FromLinearRGB(r, g, b)
+	}
+
+	// the most chroma that is still in gamut
+	lo, hi := 0.0, c.Chroma
+	for i := 0; i < hctSearchSteps; i++ {
+		mid := (lo + hi) / 2
+		if _, _, _, ok := solveHCT(c.Hue, mid, y); ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	r, g, b, _ := solveHCT(c.Hue, lo, y)
+	return FromLinearRGB(r, g, b)
+}
+
+// solveHCT finds the linear sRGB color of a CAM16 hue and chroma whose
+// relative luminance is y, reporting whether it is in gamut. The luminance
+// grows with CAM16 lightness J at a fixed hue and chroma.
+func solveHCT(hue, chroma, y float64) (r, g, b float64, ok bool) {
+	lo, hi := 0.0, 100.0
+	for i := 0; i < hctSearchSteps; i++ {
+		j := (lo + hi) / 2
+		r, g, b = cam16ToLinear(j, chroma, hue)
+		if 0.2126*r+0.7152*g+0.0722*b < y {
+			lo = j
+		} else {
+			hi = j
+		}
+	}
+
+	r, g, b = cam16ToLinear((lo+hi)/2, chroma, hue)
+	const eps = 1e-4
+	ok = r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps &&
+		math.Abs(0.2126*r+0.7152*g+0.0722*b-y) < 1e-3
+	return r, g, b, ok
+}
+
+// LstarFromY converts a relative luminance in [0, 100] to CIE L* lightness.
+func LstarFromY(y float64) float64 {
+	t := y / 100
+	if t > 216.0/24389 {
+		return 116*math.Cbrt(t) - 16
+	}
+	return 24389.0 / 27 * t
+}
+
+// YFromLstar converts a CIE L* lightness to relative luminance in [0, 100].
+func YFromLstar(lstar float64) float64 {
+	ft := (lstar + 16) / 116
+	if ft*ft*ft > 216.0/24389 {
+		return 100 * ft * ft * ft
+	}
+	return 100 * lstar / (24389.0 / 27)
+}
@@ -0,0 +1,66 @@
+package colorspace
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func Test_ToHCT(t *testing.T) {
+	// reference values from Google's material-color-utilities
+	tests := []struct {
+		input    color.RGBA
+		expected HCT
+	}{
+		{color.RGBA{255, 0, 0, 255}, HCT{Hue: 27.408, Chroma: 113.357, Tone: 53.233}},
+		{color.RGBA{0, 255, 0, 255}, HCT{Hue: 142.139, Chroma: 108.410, Tone: 87.737}},
+		{color.RGBA{0, 0, 255, 255}, HCT{Hue: 282.788, Chroma: 87.230, Tone: 32.302}},
+		{color.RGBA{255, 255, 255, 255}, HCT{Hue: 209.492, Chroma: 2.869, Tone: 100}},
+	}
+
+	for _, tt := range tests {
+		got := ToHCT(tt.input)
+		if math.Abs(got.Hue-tt.expected.Hue) > 0.01 || math.Abs(got.Chroma-tt.expected.Chroma) > 0.01 ||
+			math.Abs(got.Tone-tt.expected.Tone) > 0.01 {
+			t.Errorf("Expected %v to be %+v, got %+v", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func Test_HCTRoundTrip(t *testing.T) {
+	colors := []color.RGBA{
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{128, 128, 128, 255},
+		{234, 118, 203, 255},
+		{30, 102, 245, 255},
+		{64, 160, 43, 255},
+		{103, 80, 164, 255},
+	}
+
+	for _, c := range colors {
+		if got := ToHCT(c).ToRGBA(); got != c {
+			t.Errorf("Expected HCT round trip of %v, got %v", c, got)
+		}
+	}
+}
+
+func Test_HCTGamutMapping(t *testing.T) {
+	for _, tone := range []float64{10, 40, 90, 99} {
+		got := ToHCT(HCT{Hue: 282, Chroma: 200, Tone: tone}.ToRGBA())
+		if math.Abs(got.Tone-tone) > 0.5 {
+			t.Errorf("Expected gamut mapping to keep tone %v, got %.2f", tone, got.Tone)
+		}
+		if tone < 99 && math.Abs(got.Hue-282) > 2 {
+			t.Errorf("Expected gamut mapping to keep hue 282 at tone %v, got %.2f", tone, got.Hue)
+		}
+	}
+}
+
+func Test_Lstar(t *testing.T) {
+	for _, lstar := range []float64{0, 5, 18, 50, 99, 100} {
+		if got := LstarFromY(YFromLstar(lstar)); math.Abs(got-lstar) > 1e-9 {
+			t.Errorf("Expected L* %v to round trip, got %v", lstar, got)
+		}
+	}
+}
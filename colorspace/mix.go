@@ -0,0 +1,118 @@
+package colorspace
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Space is a color space in which colors are interpolated.
+type Space string
+
+const (
+	SpaceSRGB   Space = "srgb"
+	SpaceLinear Space = "linear"
+	SpaceOKLab  Space = "oklab"
+	SpaceOKLCh  Space = "oklch"
+	SpaceHSL    Space = "hsl"
+)
+
+// ParseSpace validates the name of an interpolation color space.
+func ParseSpace(name string) (Space, error) {
+	switch space := Space(name); space {
+	case SpaceSRGB, SpaceLinear, SpaceOKLab, SpaceOKLCh, SpaceHSL:
+		return space, nil
+	default:
+		return "", fmt.Errorf("invalid color space '%v' (expected srgb, linear, oklab, oklch or hsl)", name)
+	}
+}
+
+// Mix interpolates between two colors in the given space, t = 0 returns a
+// and t = 1 returns b. Hues in cylindrical spaces take the shorter way around,
+// a gray endpoint takes the hue of the other color.
+func Mix(a, b color.Color, t float64, space Space) color.RGBA {
+	switch space {
+	case SpaceSRGB:
+		ar, ag, ab, _ := a.RGBA()
+		br, bg, bb, _ := b.RGBA()
+		return color.RGBA{
+			R: channelToByte(lerp(float64(ar>>8), float64(br>>8), t) / 255),
+			G: channelToByte(lerp(float64(ag>>8), float64(bg>>8), t) / 255),
+			B: channelToByte(lerp(float64(ab>>8), float64(bb>>8), t) / 255),
+			A: 255,
+		}
+	case SpaceLinear:
+		ar, ag, ab := LinearRGB(a)
+		br, bg, bb := LinearRGB(b)
+		return FromLinearRGB(lerp(ar, br, t), lerp(ag, bg, t), lerp(ab, bb, t))
+	case SpaceOKLCh:
+		ac, bc := ToOKLCh(a), ToOKLCh(b)
+		if ac.IsAchromatic() {
+			ac.H = bc.H
+		} else if bc.IsAchromatic() {
+			bc.H = ac.H
+		}
+		return OKLCh{L: lerp(ac.L, bc.L, t), C: lerp(ac.C, bc.C, t), H: lerpHue(ac.H, bc.H, t)}.ToRGBA()
+	case SpaceHSL:
+		ah, bh := ToHSL(a), ToHSL(b)
+		if ah.S == 0 {
+			ah.H = bh.H
+		} else if bh.S == 0 {
+			bh.H = ah.H
+		}
+		return HSL{H: lerpHue(ah.H, bh.H, t), S: lerp(ah.S, bh.S, t), L: lerp(ah.L, bh.L, t)}.ToRGBA()
+	default:
+		al, bl := ToOKLab(a), ToOKLab(b)
+		return OKLab{L: lerp(al.L, bl.L, t), A: lerp(al.A, bl.A, t), B: lerp(al.B, bl.B, t)}.ToRGBA()
+	}
+}
+
+// Ramp returns steps colors evenly spaced from a to b, both included.
+func Ramp(a, b color.Color, steps int, space Space) []color.RGBA {
+	if steps < 2 {
+		return []color.RGBA{Mix(a, b, 0, space)}
+	}
+
+	ramp := make([]color.RGBA, steps)
+	for i := range ramp {
+		ramp[i] = Mix(a, b, float64(i)/float64(steps-1), space)
+	}
+	return ramp
+}
+
+// ToRGBA converts the color to 8-bit sRGB.
+func (c HSL) ToRGBA() color.RGBA {
+	s, l := clamp01(c.S), clamp01(c.L)
+	chroma := (1 - math.Abs(2*l-1)) * s
+	h := NormalizeHue(c.H) / 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g, b = chroma, x, 0
+	case h < 2:
+		r, g, b = x, chroma, 0
+	case h < 3:
+		r, g, b = 0, chroma, x
+	case h < 4:
+		r, g, b = 0, x, chroma
+	case h < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	m := l - chroma/2
+	return color.RGBA{R: channelToByte(r + m), G: channelToByte(g + m), B: channelToByte(b + m), A: 255}
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// lerpHue interpolates hue angles along the shorter arc
+func lerpHue(a, b, t float64) float64 {
+	diff := math.Mod(b-a+540, 360) - 180
+	return NormalizeHue(a + diff*t)
+}
@@ -0,0 +1,82 @@
+package colorspace
+
+import (
+	"image/color"
+	"testing"
+)
+
+func Test_ParseSpace(t *testing.T) {
+	for _, name := range []string{"srgb", "linear", "oklab", "oklch", "hsl"} {
+		if _, err := ParseSpace(name); err != nil {
+			t.Errorf("Expected no error for %q, got: %v", name, err)
+		}
+	}
+	if _, err := ParseSpace("cmyk"); err == nil {
+		t.Errorf("Expected error for invalid color space, got none")
+	}
+}
+
+func Test_Mix(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	tests := []struct {
+		a, b     color.RGBA
+		t        float64
+		space    Space
+		expected color.RGBA
+	}{
+		{black, white, 0.5, SpaceSRGB, color.RGBA{128, 128, 128, 255}},
+		{black, white, 0.5, SpaceLinear, color.RGBA{188, 188, 188, 255}},
+		{black, white, 0.5, SpaceHSL, color.RGBA{128, 128, 128, 255}},
+		{red, blue, 0.5, SpaceHSL, color.RGBA{255, 0, 255, 255}},
+		{red, blue, 0, SpaceOKLab, red},
+		{red, blue, 1, SpaceOKLCh, blue},
+	}
+
+	for _, tt := range tests {
+		if got := Mix(tt.a, tt.b, tt.t, tt.space); got != tt.expected {
+			t.Errorf("Expected mix of %v and %v at %v in %v to be %v, got %v",
+				tt.a, tt.b, tt.t, tt.space, tt.expected, got)
+		}
+	}
+}
+
+func Test_Ramp(t *testing.T) {
+	a := color.RGBA{0x1e, 0x1e, 0x2e, 0xff}
+	b := color.RGBA{0xcd, 0xd6, 0xf4, 0xff}
+
+	for _, space := range []Space{SpaceSRGB, SpaceLinear, SpaceOKLab, SpaceOKLCh, SpaceHSL} {
+		ramp := Ramp(a, b, 8, space)
+		if len(ramp) != 8 {
+			t.Fatalf("Expected 8 steps in %v, got %v", space, len(ramp))
+		}
+		if ramp[0] != a || ramp[7] != b {
+			t.Errorf("Expected ramp in %v to start at %v and end at %v, got %v", space, a, b, ramp)
+		}
+		for i := 1; i < len(ramp); i++ {
+			if ToOKLab(ramp[i]).L <= ToOKLab(ramp[i-1]).L {
+				t.Errorf("Expected ramp in %v to get lighter, got %v", space, ramp)
+				break
+			}
+		}
+	}
+}
+
+func Test_HSLToRGBA(t *testing.T) {
+	colors := []color.RGBA{
+		{0, 0, 0, 255},
+		{255, 255, 255, 255},
+		{234, 118, 203, 255},
+		{30, 102, 245, 255},
+		{64, 160, 43, 255},
+	}
+
+	for _, c := range colors {
+		if got := ToHSL(c).ToRGBA(); got != c {
+			t.Errorf("Expected HSL round trip of %v, got %v", c, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/imagehandling"
+	"github.com/VannRR/color-schemorator/utility"
+)
+
+// runImageCommand runs the 'csor image <command>' filters that change the
+// colors of an image
+func runImageCommand(args []string) {
+	if len(args) == 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "simulate":
+		simulate(args[1:])
+
+	case "daltonize":
+		daltonize(args[1:])
+
+	default:
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+}
+
+// simulate runs 'csor image simulate', saving how an image looks with a color
+// vision deficiency
+func simulate(args []string) {
+	flags := flag.NewFlagSet("image simulate", flag.ExitOnError)
+	imgInput := flags.String("i", "", "Path to the input image file (supported formats: jpg, jpeg, png)")
+	imgOutput := flags.String("o", "", "Path to the output image file (supported formats: jpg, jpeg, png)")
+	deficiency := flags.String("cvd", "", "Color vision deficiency: 'protanopia', 'deuteranopia' or 'tritanopia'")
+	method := flags.String("method", string(colorspace.CVDMachado), "Simulation method: 'machado' or 'brettel'")
+	severity := flags.Float64("severity", 1, "Severity of the deficiency, from 0 (normal vision) to 1")
+	flags.Parse(args)
+
+	if *imgInput == "" || *imgOutput == "" || *deficiency == "" || flags.NArg() > 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	sim := parseCVDSimulation(*deficiency, *method, *severity)
+
+	filterImage(*imgInput, *imgOutput, func(c color.RGBA) color.RGBA {
+		return sim.Simulate(c)
+	})
+}
+
+// daltonize runs 'csor image daltonize', correcting the colors of an image so
+// that people with a color vision deficiency can tell more of them apart
+func daltonize(args []string) {
+	flags := flag.NewFlagSet("image daltonize", flag.ExitOnError)
+	imgInput := flags.String("i", "", "Path to the input image file (supported formats: jpg, jpeg, png)")
+	imgOutput := flags.String("o", "", "Path to the output image file (supported formats: jpg, jpeg, png)")
+	deficiency := flags.String("cvd", "", "Color vision deficiency: 'protanopia', 'deuteranopia' or 'tritanopia'")
+	method := flags.String("method", string(colorspace.CVDMachado), "Simulation method: 'machado' or 'brettel'")
+	severity := flags.Float64("severity", 1, "Severity of the deficiency, from 0 (normal vision) to 1")
+	flags.Parse(args)
+
+	if *imgInput == "" || *imgOutput == "" || *deficiency == "" || flags.NArg() > 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	sim := parseCVDSimulation(*deficiency, *method, *severity)
+
+	filterImage(*imgInput, *imgOutput, func(c color.RGBA) color.RGBA {
+		return sim.Daltonize(c)
+	})
+}
+
+// filterImage saves the input image with the colors of its pixels passed
+// through fn
+func filterImage(imgInputPath, imgOutputPath string, fn func(c color.RGBA) color.RGBA) {
+	if err := utility.ValidateExtension(imgInputPath, "input image"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := utility.ValidateExtension(imgOutputPath, "output image"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	oldImg, err := imagehandling.GetDecodedImage(imgInputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := imagehandling.SaveNewImg(imgOutputPath, imagehandling.FilterImg(oldImg, fn)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parseCVDSimulation parses the color vision deficiency options given on the
+// command line
+func parseCVDSimulation(deficiencyName, methodName string, severity float64) colorspace.CVDSimulation {
+	deficiency, err := colorspace.ParseDeficiency(deficiencyName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	method, err := colorspace.ParseCVDMethod(methodName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if severity < 0 || severity > 1 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	return colorspace.CVDSimulation{Deficiency: deficiency, Method: method, Severity: severity}
+}
@@ -10,13 +10,95 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/VannRR/color-schemorator/parsepalette"
 	"github.com/VannRR/color-schemorator/utility"
 )
 
-const maxImgFileSizeMB = 15
+const (
+	maxImgFileSizeMB = 15
+
+	// DefaultAlphaThreshold skips pixels that are more than half transparent.
+	DefaultAlphaThreshold uint8 = 128
+
+	// MaxPalettedColors is the most colors an image.Paletted can hold.
+	MaxPalettedColors = 256
+)
+
+// ExtractOptions controls which pixels of an image are counted when
+// extracting its palette.
+type ExtractOptions struct {
+	// AlphaThreshold is the minimum alpha (0-255) a pixel needs to be counted.
+	// Counted pixels are un-premultiplied and treated as opaque, fully
+	// transparent pixels are never counted.
+	AlphaThreshold uint8
+	// Region limits extraction to a rectangle relative to the image's top left
+	// corner. An empty region selects the whole image.
+	Region image.Rectangle
+	// Mask selects the pixels to count, it must have the same size as the image.
+	// Pixels where the mask's gray value is at least half bright are counted.
+	Mask image.Image
+	// MaxColors is the most colors extracted, keeping the most common ones.
+	// Zero keeps every color.
+	MaxColors int
+}
+
+// Validate checks that the region and mask of the options fit the image.
+func (opts ExtractOptions) Validate(inputImage image.Image) error {
+	bounds := inputImage.Bounds()
+	if !opts.Region.Empty() && opts.countBounds(bounds).Empty() {
+		return fmt.Errorf("region %v does not overlap the %vx%v image",
+			opts.Region, bounds.Dx(), bounds.Dy())
+	}
+	if opts.Mask != nil && opts.Mask.Bounds().Size() != bounds.Size() {
+		return fmt.Errorf("mask size %v does not match image size %v",
+			opts.Mask.Bounds().Size(), bounds.Size())
+	}
+	return nil
+}
+
+// countBounds returns the part of the image bounds selected by the region
+func (opts ExtractOptions) countBounds(bounds image.Rectangle) image.Rectangle {
+	if opts.Region.Empty() {
+		return bounds
+	}
+	return opts.Region.Add(bounds.Min).Intersect(bounds)
+}
+
+// masked reports whether the mask excludes the pixel at x, y of the image bounds
+func (opts ExtractOptions) masked(bounds image.Rectangle, x, y int) bool {
+	if opts.Mask == nil {
+		return false
+	}
+	maskMin := opts.Mask.Bounds().Min
+	gray := color.GrayModel.Convert(opts.Mask.At(maskMin.X+x-bounds.Min.X, maskMin.Y+y-bounds.Min.Y)).(color.Gray)
+	return gray.Y < 128
+}
+
+// ParseRegion parses a region given as 'x,y,w,h' on the command line.
+func ParseRegion(regionString string) (image.Rectangle, error) {
+	parts := strings.Split(regionString, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("invalid region '%v' (expected x,y,w,h)", regionString)
+	}
+
+	var values [4]int
+	for i, part := range parts {
+		value, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || value < 0 {
+			return image.Rectangle{}, fmt.Errorf("invalid region '%v' (expected x,y,w,h)", regionString)
+		}
+		values[i] = value
+	}
+	if values[2] == 0 || values[3] == 0 {
+		return image.Rectangle{}, fmt.Errorf("invalid region '%v' (width and height must be positive)", regionString)
+	}
+
+	return image.Rect(values[0], values[1], values[0]+values[2], values[1]+values[3]), nil
+}
 
 // GetDecodedImage opens, validates, and decodes an image from the given file path.
 // It returns the decoded image or an error if any step fails.
@@ -47,23 +129,125 @@ func GetDecodedImage(filePathString string) (image.Image, error) {
 	return img, nil
 }
 
-// ExtractPalette extracts the most common colors from an image, returning them as a color.Palette.
+// ColorCount is a color found in an image together with the number of
+// pixels that have it.
+type ColorCount struct {
+	Color color.RGBA
+	Count uint32
+}
+
+// ExtractPalette extracts the most common colors from an image, up to
+// parsepalette.DefaultMaxColors, returning them as a color.Palette.
 func ExtractPalette(inputImage image.Image) color.Palette {
-	bounds := inputImage.Bounds()
-	numCPU := runtime.NumCPU()
-	stripWidth := (bounds.Max.X - bounds.Min.X) / numCPU
+	colorCounts := CountColors(inputImage, ExtractOptions{AlphaThreshold: DefaultAlphaThreshold})
+
+	var palette color.Palette
+	for i := 0; i < parsepalette.DefaultMaxColors && i < len(colorCounts); i++ {
+		palette = append(palette, colorCounts[i].Color)
+	}
+
+	return palette
+}
+
+// PaletteCoverage merges the weighted color coverage of the images of a
+// combined extraction one image at a time, so that each image can be dropped
+// as soon as its colors are counted. Each image's coverage is scaled by its
+// weight, so an image contributes in proportion to its weight regardless of
+// its resolution.
+type PaletteCoverage struct {
+	opts        ExtractOptions
+	coverage    map[color.RGBA]float64
+	totalWeight float64
+}
+
+// NewPaletteCoverage returns an empty coverage counting pixels selected by opts
+func NewPaletteCoverage(opts ExtractOptions) *PaletteCoverage {
+	return &PaletteCoverage{opts: opts, coverage: make(map[color.RGBA]float64)}
+}
+
+// Add counts the colors of an image, scaling its coverage by weight. Images
+// without counted pixels and weights of 0 or less are skipped.
+func (p *PaletteCoverage) Add(img image.Image, weight float64) {
+	colorMap := countColorMap(img, p.opts)
+
+	var total uint64
+	for _, count := range colorMap {
+		total += uint64(count)
+	}
+	if total == 0 || weight <= 0 {
+		return
+	}
+
+	for c, count := range colorMap {
+		p.coverage[c] += weight * float64(count) / float64(total)
+	}
+	p.totalWeight += weight
+}
+
+// Entries returns the most common colors of the added images, recording the
+// share of the total weight each color covers
+func (p *PaletteCoverage) Entries() []parsepalette.Entry {
+	entries := make([]parsepalette.Entry, 0, len(p.coverage))
+	for c, weight := range p.coverage {
+		entries = append(entries, parsepalette.Entry{Color: c, Weight: weight / p.totalWeight})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Weight != entries[j].Weight {
+			return entries[i].Weight > entries[j].Weight
+		}
+		return packRGBA(entries[i].Color) < packRGBA(entries[j].Color)
+	})
+
+	if p.opts.MaxColors > 0 && len(entries) > p.opts.MaxColors {
+		entries = entries[:p.opts.MaxColors]
+	}
+
+	return entries
+}
 
-	type colorCount struct {
-		color color.RGBA
-		count uint32
+// CountColors counts the occurrences of every color in an image, skipping
+// pixels below the alpha threshold or outside of the region and mask.
+// The result is sorted by descending count, ties are broken by the color value
+// so the order is the same between runs.
+func CountColors(inputImage image.Image, opts ExtractOptions) []ColorCount {
+	colorMap := countColorMap(inputImage, opts)
+
+	colorCounts := make([]ColorCount, 0, len(colorMap))
+	for c, count := range colorMap {
+		colorCounts = append(colorCounts, ColorCount{Color: c, Count: count})
 	}
 
+	sort.Slice(colorCounts, func(i, j int) bool {
+		if colorCounts[i].Count != colorCounts[j].Count {
+			return colorCounts[i].Count > colorCounts[j].Count
+		}
+		return packRGBA(colorCounts[i].Color) < packRGBA(colorCounts[j].Color)
+	})
+
+	return colorCounts
+}
+
+// countColorMap counts the colors of an image in parallel strips,
+// merging the per strip color maps into one
+func countColorMap(inputImage image.Image, opts ExtractOptions) map[color.RGBA]uint32 {
+	imageBounds := inputImage.Bounds()
+	bounds := opts.countBounds(imageBounds)
+	numCPU := runtime.NumCPU()
+	stripWidth := (bounds.Max.X - bounds.Min.X) / numCPU
+
 	processStrip := func(startX, endX int, colorMap map[color.RGBA]uint32, wg *sync.WaitGroup) {
 		defer wg.Done()
 
 		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
 			for x := startX; x < endX; x++ {
-				rgba := rgbaAt(inputImage, x, y)
+				if opts.masked(imageBounds, x, y) {
+					continue
+				}
+				rgba, ok := opaqueRGBAAt(inputImage, x, y, opts.AlphaThreshold)
+				if !ok {
+					continue
+				}
 				colorMap[rgba]++
 			}
 		}
@@ -96,34 +280,148 @@ func ExtractPalette(inputImage image.Image) color.Palette {
 		}
 	}
 
-	var colorCountSlice []colorCount
-	for c, count := range finalColorMap {
-		colorCountSlice = append(colorCountSlice, colorCount{color: c, count: count})
+	return finalColorMap
+}
+
+// packRGBA packs a color into a single integer, used as a deterministic tie-break
+func packRGBA(c color.RGBA) uint32 {
+	return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+}
+
+// opaqueRGBAAt extracts the un-premultiplied, opaque RGBA color at a given pixel
+// location, reporting false if the pixel is transparent or below minAlpha
+func opaqueRGBAAt(img image.Image, x, y int, minAlpha uint8) (color.RGBA, bool) {
+	r, g, b, a := img.At(x, y).RGBA()
+	if a == 0 || a>>8 < uint32(minAlpha) {
+		return color.RGBA{}, false
 	}
+	if a != 0xffff {
+		r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+	}
+	return color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 255}, true
+}
 
-	sort.Slice(colorCountSlice, func(i, j int) bool {
-		return colorCountSlice[i].count > colorCountSlice[j].count
+// GenerateNewImg creates a new image by applying a color palette to the old image.
+// The function leverages parallel processing to enhance performance. A paletted
+// image holds at most MaxPalettedColors colors.
+func GenerateNewImg(oldImg image.Image, palette color.Palette) *image.Paletted {
+	newImg := image.NewPaletted(oldImg.Bounds(), palette)
+	forEachStrip(oldImg.Bounds(), func(x, y int) {
+		newImg.Set(x, y, palette.Convert(oldImg.At(x, y)))
 	})
+	return newImg
+}
 
-	var palette color.Palette
-	for i := 0; i < parsepalette.MaxColors && i < len(colorCountSlice); i++ {
-		palette = append(palette, colorCountSlice[i].color)
+// GenerateTruecolorImg creates a new image like GenerateNewImg, without the
+// MaxPalettedColors limit of paletted images.
+func GenerateTruecolorImg(oldImg image.Image, palette color.Palette) *image.RGBA {
+	newImg := image.NewRGBA(oldImg.Bounds())
+	forEachStrip(oldImg.Bounds(), func(x, y int) {
+		newImg.Set(x, y, palette.Convert(oldImg.At(x, y)))
+	})
+	return newImg
+}
+
+// PaletteUsage maps every pixel of an image to its closest palette color the
+// same way GenerateNewImg does, returning the palette colors used with the
+// share of the image's pixels each replaces, most used first.
+func PaletteUsage(inputImage image.Image, palette color.Palette) []parsepalette.Entry {
+	bounds := inputImage.Bounds()
+	numCPU := runtime.NumCPU()
+	stripWidth := (bounds.Max.X - bounds.Min.X) / numCPU
+
+	processStrip := func(startX, endX int, indexCounts []uint64, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := startX; x < endX; x++ {
+				indexCounts[palette.Index(inputImage.At(x, y))]++
+			}
+		}
 	}
 
-	return palette
+	var wg sync.WaitGroup
+	stripCounts := make([][]uint64, numCPU)
+
+	for i := 0; i < numCPU; i++ {
+		stripCounts[i] = make([]uint64, len(palette))
+		startX := bounds.Min.X + i*stripWidth
+		endX := startX + stripWidth
+		if i == numCPU-1 {
+			endX = bounds.Max.X // Handle the last strip to ensure full width is covered
+		}
+
+		wg.Add(1)
+		go processStrip(startX, endX, stripCounts[i], &wg)
+	}
+
+	wg.Wait()
+
+	counts := make(map[color.RGBA]uint64)
+	var total uint64
+	for _, indexCounts := range stripCounts {
+		for i, count := range indexCounts {
+			if count > 0 {
+				counts[color.RGBAModel.Convert(palette[i]).(color.RGBA)] += count
+				total += count
+			}
+		}
+	}
+
+	entries := make([]parsepalette.Entry, 0, len(counts))
+	for c, count := range counts {
+		entries = append(entries, parsepalette.Entry{Color: c, Weight: float64(count) / float64(total)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Weight != entries[j].Weight {
+			return entries[i].Weight > entries[j].Weight
+		}
+		return packRGBA(entries[i].Color) < packRGBA(entries[j].Color)
+	})
+	return entries
 }
 
-// rgbaAt extracts the RGBA color at a given pixel location
-func rgbaAt(img image.Image, x, y int) color.RGBA {
-	r, g, b, a := img.At(x, y).RGBA()
-	return color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(a >> 8)}
+// RemapImgColors replaces the colors of a generated image found in remap. A
+// paletted image only has its palette changed.
+func RemapImgColors(img image.Image, remap map[color.RGBA]color.RGBA) image.Image {
+	if paletted, ok := img.(*image.Paletted); ok {
+		palette := make(color.Palette, len(paletted.Palette))
+		for i, c := range paletted.Palette {
+			palette[i] = c
+			if replacement, exists := remap[color.RGBAModel.Convert(c).(color.RGBA)]; exists {
+				palette[i] = replacement
+			}
+		}
+		paletted.Palette = palette
+		return paletted
+	}
+
+	return FilterImg(img, func(c color.RGBA) color.RGBA {
+		if replacement, exists := remap[c]; exists {
+			return replacement
+		}
+		return c
+	})
 }
 
-// GenerateNewImg creates a new image by applying a color palette to the old image.
-// The function leverages parallel processing to enhance performance.
-func GenerateNewImg(oldImg image.Image, palette color.Palette) *image.Paletted {
-	bounds := oldImg.Bounds()
-	newImg := image.NewPaletted(bounds, palette)
+// FilterImg creates a new image by passing the color of every pixel of the old
+// image through fn, keeping the pixel's alpha.
+func FilterImg(oldImg image.Image, fn func(color.RGBA) color.RGBA) *image.NRGBA {
+	newImg := image.NewNRGBA(oldImg.Bounds())
+	forEachStrip(oldImg.Bounds(), func(x, y int) {
+		c := color.NRGBAModel.Convert(oldImg.At(x, y)).(color.NRGBA)
+		if c.A == 0 {
+			return
+		}
+		filtered := fn(color.RGBA{R: c.R, G: c.G, B: c.B, A: 255})
+		newImg.SetNRGBA(x, y, color.NRGBA{R: filtered.R, G: filtered.G, B: filtered.B, A: c.A})
+	})
+	return newImg
+}
+
+// forEachStrip calls fn for every pixel of the bounds, splitting the image
+// into vertical strips processed in parallel
+func forEachStrip(bounds image.Rectangle, fn func(x, y int)) {
 	numCPU := runtime.NumCPU()
 
 	stripWidth := (bounds.Max.X - bounds.Min.X) / numCPU
@@ -133,7 +431,7 @@ func GenerateNewImg(oldImg image.Image, palette color.Palette) *image.Paletted {
 		defer wg.Done()
 		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
 			for x := startX; x < endX; x++ {
-				newImg.Set(x, y, palette.Convert(oldImg.At(x, y)))
+				fn(x, y)
 			}
 		}
 	}
@@ -150,12 +448,11 @@ func GenerateNewImg(oldImg image.Image, palette color.Palette) *image.Paletted {
 	}
 
 	wg.Wait()
-	return newImg
 }
 
-// SaveNewImg saves the new paletted image to the specified file path.
-// It supports saving in JPEG or PNG formats.
-func SaveNewImg(filePathString string, newImg *image.Paletted) error {
+// SaveNewImg saves the new paletted or truecolor image to the specified file
+// path. It supports saving in JPEG or PNG formats.
+func SaveNewImg(filePathString string, newImg image.Image) error {
 	outputFile, err := os.Create(filePathString)
 	if err != nil {
 		return fmt.Errorf("failed to create file %s: %w", filePathString, err)
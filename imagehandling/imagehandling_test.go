@@ -3,6 +3,7 @@ package imagehandling
 import (
 	"image"
 	"image/color"
+	"image/draw"
 	"slices"
 	"testing"
 
@@ -128,3 +129,318 @@ func Test_SaveNewImg(t *testing.T) {
 		t.Errorf("Expected no error, got error: %v", err)
 	}
 }
+
+func Test_CountColors(t *testing.T) {
+	a := color.RGBA{0x10, 0x20, 0x30, 0xff}
+	b := color.RGBA{0x40, 0x50, 0x60, 0xff}
+	c := color.RGBA{0x70, 0x80, 0x90, 0xff}
+
+	// c covers half of the image, a and b tie on the other half
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			switch {
+			case y < 2:
+				img.Set(x, y, c)
+			case x < 2:
+				img.Set(x, y, b)
+			default:
+				img.Set(x, y, a)
+			}
+		}
+	}
+
+	expected := []ColorCount{{c, 8}, {a, 4}, {b, 4}}
+
+	for run := 0; run < 10; run++ {
+		actual := CountColors(img, ExtractOptions{AlphaThreshold: DefaultAlphaThreshold})
+		if !slices.Equal(expected, actual) {
+			t.Fatalf("Expected counts %v, got %v", expected, actual)
+		}
+	}
+}
+
+func Test_CountColorsAlpha(t *testing.T) {
+	opaque := color.RGBA{0x40, 0x80, 0xc0, 0xff}
+
+	// the top row is transparent, the middle row is mostly transparent and the
+	// bottom row is the opaque color at 80% alpha
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 3))
+	for x := 0; x < 4; x++ {
+		img.Set(x, 0, color.NRGBA{0, 0, 0, 0})
+		img.Set(x, 1, color.NRGBA{0xff, 0x00, 0x00, 0x10})
+		img.Set(x, 2, color.NRGBA{0x40, 0x80, 0xc0, 0xcc})
+	}
+
+	expected := []ColorCount{{opaque, 4}}
+	actual := CountColors(img, ExtractOptions{AlphaThreshold: DefaultAlphaThreshold})
+	if !slices.Equal(expected, actual) {
+		t.Errorf("Expected counts %v, got %v", expected, actual)
+	}
+
+	actual = CountColors(img, ExtractOptions{AlphaThreshold: 0})
+	if len(actual) != 2 {
+		t.Errorf("Expected 2 colors without an alpha threshold, got %v", actual)
+	}
+	for _, cc := range actual {
+		if cc.Color.A != 0xff {
+			t.Errorf("Expected only opaque colors, got %v", cc.Color)
+		}
+	}
+}
+
+func Test_CountColorsRegionAndMask(t *testing.T) {
+	sky := color.RGBA{0x87, 0xce, 0xeb, 0xff}
+	subject := color.RGBA{0xea, 0x76, 0xcb, 0xff}
+
+	// sky everywhere except a 2x2 subject in the bottom right corner
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	mask := image.NewGray(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, sky)
+			if x >= 2 && y >= 2 {
+				img.Set(x, y, subject)
+				mask.Set(x, y, color.Gray{Y: 0xff})
+			}
+		}
+	}
+
+	tests := []struct {
+		opts     ExtractOptions
+		expected []ColorCount
+	}{
+		{ExtractOptions{Region: image.Rect(1, 1, 4, 4)}, []ColorCount{{sky, 5}, {subject, 4}}},
+		{ExtractOptions{Region: image.Rect(2, 2, 10, 10)}, []ColorCount{{subject, 4}}},
+		{ExtractOptions{Mask: mask}, []ColorCount{{subject, 4}}},
+		{ExtractOptions{Region: image.Rect(0, 0, 3, 3), Mask: mask}, []ColorCount{{subject, 1}}},
+	}
+
+	for _, tt := range tests {
+		if err := tt.opts.Validate(img); err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+		actual := CountColors(img, tt.opts)
+		if !slices.Equal(tt.expected, actual) {
+			t.Errorf("Expected counts %v, got %v", tt.expected, actual)
+		}
+	}
+
+	if err := (ExtractOptions{Region: image.Rect(5, 5, 6, 6)}).Validate(img); err == nil {
+		t.Errorf("Expected error for region outside of the image, got none")
+	}
+	if err := (ExtractOptions{Mask: image.NewGray(image.Rect(0, 0, 2, 2))}).Validate(img); err == nil {
+		t.Errorf("Expected error for mask of a different size, got none")
+	}
+}
+
+func Test_ParseRegion(t *testing.T) {
+	tests := []struct {
+		regionString string
+		expected     image.Rectangle
+		isError      bool
+	}{
+		{"0,0,10,20", image.Rect(0, 0, 10, 20), false},
+		{"5, 6, 7, 8", image.Rect(5, 6, 12, 14), false},
+		{"1,2,3", image.Rectangle{}, true},
+		{"1,2,0,4", image.Rectangle{}, true},
+		{"-1,2,3,4", image.Rectangle{}, true},
+		{"a,b,c,d", image.Rectangle{}, true},
+	}
+
+	for _, tt := range tests {
+		region, err := ParseRegion(tt.regionString)
+		if err != nil && !tt.isError {
+			t.Errorf("Expected no error for input %v, but got: %v", tt.regionString, err)
+		} else if err == nil && tt.isError {
+			t.Errorf("Expected error for input %v, but got none", tt.regionString)
+		} else if region != tt.expected {
+			t.Errorf("Expected region %v for input %v, but got %v", tt.expected, tt.regionString, region)
+		}
+	}
+}
+
+func Test_PaletteCoverage(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	blue := color.RGBA{0x00, 0x00, 0xff, 0xff}
+
+	solid := func(c color.RGBA, size int) image.Image {
+		img := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.Draw(img, img.Bounds(), &image.Uniform{c}, image.Point{}, draw.Src)
+		return img
+	}
+
+	// a large all red image and a small all blue image
+	tests := []struct {
+		weights  [2]float64
+		expected []parsepalette.Entry
+	}{
+		{[2]float64{1, 1}, []parsepalette.Entry{{Color: blue, Weight: 0.5}, {Color: red, Weight: 0.5}}},
+		{[2]float64{1, 3}, []parsepalette.Entry{{Color: blue, Weight: 0.75}, {Color: red, Weight: 0.25}}},
+	}
+
+	for _, tt := range tests {
+		coverage := NewPaletteCoverage(ExtractOptions{AlphaThreshold: DefaultAlphaThreshold})
+		coverage.Add(solid(red, 8), tt.weights[0])
+		coverage.Add(solid(blue, 2), tt.weights[1])
+		if actual := coverage.Entries(); !slices.Equal(tt.expected, actual) {
+			t.Errorf("Weights %v: expected entries %v, got %v", tt.weights, tt.expected, actual)
+		}
+	}
+
+	// a directory of 4 red images sharing a weight of 1 against a single blue
+	// image of weight 1, added one image at a time
+	coverage := NewPaletteCoverage(ExtractOptions{AlphaThreshold: DefaultAlphaThreshold})
+	for i := 0; i < 4; i++ {
+		coverage.Add(solid(red, 4+i), 0.25)
+	}
+	coverage.Add(solid(blue, 2), 1)
+
+	expected := []parsepalette.Entry{{Color: blue, Weight: 0.5}, {Color: red, Weight: 0.5}}
+	if actual := coverage.Entries(); !slices.Equal(expected, actual) {
+		t.Errorf("Expected entries %v, got %v", expected, actual)
+	}
+}
+
+func Test_PaletteCoverageMaxColors(t *testing.T) {
+	// 400 distinct colors, one pixel each
+	img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for i := 0; i < 400; i++ {
+		img.Set(i%20, i/20, color.RGBA{uint8(i % 256), uint8(i / 256), 0x80, 0xff})
+	}
+
+	tests := []struct {
+		maxColors int
+		expected  int
+	}{
+		{0, 400},
+		{parsepalette.DefaultMaxColors, 256},
+		{16, 16},
+	}
+
+	for _, tt := range tests {
+		coverage := NewPaletteCoverage(ExtractOptions{AlphaThreshold: DefaultAlphaThreshold, MaxColors: tt.maxColors})
+		coverage.Add(img, 1)
+		if actual := coverage.Entries(); len(actual) != tt.expected {
+			t.Errorf("Max colors %v: expected %v entries, got %v", tt.maxColors, tt.expected, len(actual))
+		}
+	}
+
+	if actual := ExtractPalette(img); len(actual) != parsepalette.DefaultMaxColors {
+		t.Errorf("Expected %v colors, got %v", parsepalette.DefaultMaxColors, len(actual))
+	}
+}
+
+func Test_GenerateTruecolorImg(t *testing.T) {
+	oldImg, err := GetDecodedImage(testImgInputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	palette := make(color.Palette, 0, 512)
+	for i := 0; i < 512; i++ {
+		palette = append(palette, color.RGBA{uint8(i % 8 * 36), uint8(i / 8 % 8 * 36), uint8(i / 64 * 36), 0xff})
+	}
+
+	newImg := GenerateTruecolorImg(oldImg, palette)
+	bounds := oldImg.Bounds()
+	if newImg.Bounds() != bounds {
+		t.Fatalf("Expected bounds %v, got %v", bounds, newImg.Bounds())
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if expected := palette.Convert(oldImg.At(x, y)); newImg.At(x, y) != expected {
+				t.Fatalf("Expected %v at %v,%v, got %v", expected, x, y, newImg.At(x, y))
+			}
+		}
+	}
+}
+
+func Test_FilterImg(t *testing.T) {
+	oldImg := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	oldImg.SetNRGBA(0, 0, color.NRGBA{0x10, 0x20, 0x30, 0xff})
+	oldImg.SetNRGBA(1, 0, color.NRGBA{0x40, 0x50, 0x60, 0x80})
+
+	invert := func(c color.RGBA) color.RGBA {
+		return color.RGBA{0xff - c.R, 0xff - c.G, 0xff - c.B, c.A}
+	}
+	newImg := FilterImg(oldImg, invert)
+
+	expected := []color.NRGBA{{0xef, 0xdf, 0xcf, 0xff}, {0xbf, 0xaf, 0x9f, 0x80}}
+	for x, want := range expected {
+		if got := newImg.NRGBAAt(x, 0); got != want {
+			t.Errorf("Expected %v at %v,0, got %v", want, x, got)
+		}
+	}
+}
+
+func Test_PaletteUsage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 1))
+	img.Set(0, 0, color.RGBA{0x10, 0x10, 0x10, 0xff})
+	img.Set(1, 0, color.RGBA{0x20, 0x20, 0x20, 0xff})
+	img.Set(2, 0, color.RGBA{0x30, 0x30, 0x30, 0xff})
+	img.Set(3, 0, color.RGBA{0xf0, 0xf0, 0xf0, 0xff})
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+	expected := []parsepalette.Entry{{Color: black, Weight: 0.75}, {Color: white, Weight: 0.25}}
+	if got := PaletteUsage(img, color.Palette{white, black}); !slices.Equal(got, expected) {
+		t.Errorf("Expected palette usage %v, got %v", expected, got)
+	}
+}
+
+func Test_PaletteUsageMatchesGenerateNewImg(t *testing.T) {
+	// a light gray at half alpha is closer to white un-premultiplied, but
+	// GenerateNewImg maps it to black
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0xe0, 0xe0, 0xe0, 0x80})
+	img.SetNRGBA(1, 0, color.NRGBA{0xf0, 0xf0, 0xf0, 0xff})
+	img.SetNRGBA(2, 0, color.NRGBA{0x10, 0x10, 0x10, 0xff})
+	img.SetNRGBA(3, 0, color.NRGBA{0xff, 0xff, 0xff, 0x00})
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	palette := color.Palette{white, black}
+
+	generated := make(map[color.RGBA]float64)
+	newImg := GenerateNewImg(img, palette)
+	for x := 0; x < 4; x++ {
+		generated[color.RGBAModel.Convert(newImg.At(x, 0)).(color.RGBA)] += 0.25
+	}
+
+	usage := PaletteUsage(img, palette)
+	if len(usage) != len(generated) {
+		t.Fatalf("Expected palette usage %v, got %v", generated, usage)
+	}
+	for _, e := range usage {
+		if e.Weight != generated[e.Color] {
+			t.Errorf("Expected %v to replace %v of the pixels, got %v", e.Color, generated[e.Color], e.Weight)
+		}
+	}
+}
+
+func Test_RemapImgColors(t *testing.T) {
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	remap := map[color.RGBA]color.RGBA{black: red}
+
+	palette := color.Palette{black, white}
+	paletted := image.NewPaletted(image.Rect(0, 0, 2, 1), palette)
+	paletted.SetColorIndex(1, 0, 1)
+	truecolor := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	truecolor.Set(0, 0, black)
+	truecolor.Set(1, 0, white)
+
+	for _, img := range []image.Image{paletted, truecolor} {
+		newImg := RemapImgColors(img, remap)
+		if got := color.RGBAModel.Convert(newImg.At(0, 0)); got != red {
+			t.Errorf("Expected %T pixel to be remapped to %v, got %v", img, red, got)
+		}
+		if got := color.RGBAModel.Convert(newImg.At(1, 0)); got != white {
+			t.Errorf("Expected %T pixel to be kept %v, got %v", img, white, got)
+		}
+	}
+	if palette[0] != black {
+		t.Errorf("Expected the original palette to be left alone, got %v", palette[0])
+	}
+}
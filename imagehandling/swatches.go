@@ -0,0 +1,237 @@
+package imagehandling
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// minSwatchFill is the share of a cell that has to be of one exact color for
+// the cell to be a swatch, which leaves room for labels drawn inside of it.
+const minSwatchFill = 0.8
+
+// swatchArea is a connected area of one exact color in a swatch image
+type swatchArea struct {
+	bounds image.Rectangle
+	area   int
+	color  color.RGBA
+}
+
+// swatchCell is a grid cell of one swatch color
+type swatchCell struct {
+	bounds image.Rectangle
+	color  color.RGBA
+}
+
+// ReadSwatchPalette reads the palette of a swatch image, such as a strip or
+// grid of color cells, returning the color of each cell in reading order.
+// Unlike ExtractPalette it ignores borders, backgrounds and anti-aliasing,
+// only uniform rectangular cells of the swatch cell size are treated as
+// swatches and each keeps its exact color. Cells repeating the color of an
+// earlier cell are left out of the palette and counted in repeated. More than
+// maxColors swatch colors is an error unless it is 0.
+func ReadSwatchPalette(inputImage image.Image, maxColors int) (palette color.Palette, repeated int, err error) {
+	areas, labels := findSwatchAreas(inputImage)
+	cells := splitSwatchCells(areas, labels, inputImage.Bounds().Dx(), swatchCellSize(areas))
+	sortReadingOrder(cells)
+
+	seenColors := make(map[color.RGBA]struct{})
+	for _, cell := range cells {
+		if _, exists := seenColors[cell.color]; exists {
+			repeated++
+			continue
+		}
+		seenColors[cell.color] = struct{}{}
+		palette = append(palette, cell.color)
+	}
+
+	if len(palette) < parsepalette.MinColors {
+		return nil, 0, fmt.Errorf("found %v swatch colors in image, minimum amount of colors in palette is %v",
+			len(palette), parsepalette.MinColors)
+	}
+	if maxColors > 0 && len(palette) > maxColors {
+		return nil, 0, fmt.Errorf("found %v swatch colors in image, max amount of colors in palette is %v",
+			len(palette), maxColors)
+	}
+
+	return palette, repeated, nil
+}
+
+// findSwatchAreas flood fills the opaque pixels of the image into connected
+// areas of exactly one color, returning the areas and the area index of every
+// pixel, -1 for transparent pixels
+func findSwatchAreas(inputImage image.Image) ([]swatchArea, []int) {
+	bounds := inputImage.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	pixels := make([]color.RGBA, width*height)
+	labels := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c, opaque := opaqueRGBAAt(inputImage, bounds.Min.X+x, bounds.Min.Y+y, DefaultAlphaThreshold)
+			pixels[y*width+x] = c
+			labels[y*width+x] = -2
+			if !opaque {
+				labels[y*width+x] = -1
+			}
+		}
+	}
+
+	var areas []swatchArea
+	var queue []int
+
+	for start := range pixels {
+		if labels[start] != -2 {
+			continue
+		}
+
+		index := len(areas)
+		area := swatchArea{
+			bounds: image.Rect(start%width, start/width, start%width+1, start/width+1),
+			color:  pixels[start],
+		}
+		labels[start] = index
+		queue = append(queue[:0], start)
+
+		for len(queue) > 0 {
+			i := queue[len(queue)-1]
+			queue = queue[:len(queue)-1]
+
+			x, y := i%width, i/width
+			area.area++
+			area.bounds = area.bounds.Union(image.Rect(x, y, x+1, y+1))
+
+			for _, n := range [4][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+				if n[0] < 0 || n[0] >= width || n[1] < 0 || n[1] >= height {
+					continue
+				}
+				j := n[1]*width + n[0]
+				if labels[j] != -2 || pixels[j] != area.color {
+					continue
+				}
+				labels[j] = index
+				queue = append(queue, j)
+			}
+		}
+
+		areas = append(areas, area)
+	}
+
+	return areas, labels
+}
+
+// swatchCellSize finds the size of a swatch cell, the most common size of the
+// areas that fill most of their bounding box and are not much smaller than
+// the largest of them, such as anti-aliasing, borders and label glyphs.
+// Neighbouring cells of the same color form larger areas, so ties go to the
+// smaller size.
+func swatchCellSize(areas []swatchArea) image.Point {
+	filled := func(a swatchArea) bool {
+		return float64(a.area) >= minSwatchFill*float64(a.bounds.Dx()*a.bounds.Dy())
+	}
+
+	largest := 0
+	for _, a := range areas {
+		if filled(a) {
+			largest = max(largest, min(a.bounds.Dx(), a.bounds.Dy()))
+		}
+	}
+
+	counts := make(map[image.Point]int)
+	for _, a := range areas {
+		if filled(a) && 2*min(a.bounds.Dx(), a.bounds.Dy()) >= largest {
+			counts[a.bounds.Size()]++
+		}
+	}
+
+	var size image.Point
+	for s, count := range counts {
+		best := counts[size]
+		if count > best || count == best && (s.X*s.Y < size.X*size.Y || s.X*s.Y == size.X*size.Y && s.X < size.X) {
+			size = s
+		}
+	}
+	return size
+}
+
+// splitSwatchCells splits every area whose bounding box is a whole number of
+// cells into those cells, keeping the cells the area fills. Areas of any other
+// size, like the background around the swatches, a frame or a label, hold no
+// cells.
+func splitSwatchCells(areas []swatchArea, labels []int, width int, cellSize image.Point) []swatchCell {
+	if cellSize.X == 0 || cellSize.Y == 0 {
+		return nil
+	}
+
+	var cells []swatchCell
+	for index, a := range areas {
+		if a.bounds.Dx()%cellSize.X != 0 || a.bounds.Dy()%cellSize.Y != 0 {
+			continue
+		}
+
+		for y := a.bounds.Min.Y; y < a.bounds.Max.Y; y += cellSize.Y {
+			for x := a.bounds.Min.X; x < a.bounds.Max.X; x += cellSize.X {
+				cell := image.Rect(x, y, x+cellSize.X, y+cellSize.Y)
+				filled := 0
+				for py := cell.Min.Y; py < cell.Max.Y; py++ {
+					for px := cell.Min.X; px < cell.Max.X; px++ {
+						if labels[py*width+px] == index {
+							filled++
+						}
+					}
+				}
+				if float64(filled) >= minSwatchFill*float64(cellSize.X*cellSize.Y) {
+					cells = append(cells, swatchCell{bounds: cell, color: a.color})
+				}
+			}
+		}
+	}
+
+	return cells
+}
+
+// sortReadingOrder sorts cells into rows, top to bottom, and each row left
+// to right. A cell belongs to a row if its vertical center lies within the
+// first cell of that row.
+func sortReadingOrder(cells []swatchCell) {
+	sort.SliceStable(cells, func(i, j int) bool {
+		return cells[i].bounds.Min.Y < cells[j].bounds.Min.Y
+	})
+
+	row := make([]int, len(cells))
+	for i := range cells {
+		if i == 0 {
+			continue
+		}
+		rowStart := i - 1
+		for rowStart > 0 && row[rowStart-1] == row[i-1] {
+			rowStart--
+		}
+		centerY := (cells[i].bounds.Min.Y + cells[i].bounds.Max.Y) / 2
+		if centerY < cells[rowStart].bounds.Max.Y {
+			row[i] = row[i-1]
+		} else {
+			row[i] = row[i-1] + 1
+		}
+	}
+
+	order := make([]int, len(cells))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if row[order[a]] != row[order[b]] {
+			return row[order[a]] < row[order[b]]
+		}
+		return cells[order[a]].bounds.Min.X < cells[order[b]].bounds.Min.X
+	})
+
+	sorted := make([]swatchCell, len(cells))
+	for i, idx := range order {
+		sorted[i] = cells[idx]
+	}
+	copy(cells, sorted)
+}
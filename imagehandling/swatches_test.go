@@ -0,0 +1,155 @@
+package imagehandling
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_ReadSwatchPaletteStrip(t *testing.T) {
+	expected := color.Palette{
+		color.RGBA{0x1e, 0x1e, 0x2e, 0xff},
+		color.RGBA{0xf3, 0x8b, 0xa8, 0xff},
+		color.RGBA{0xa6, 0xe3, 0xa1, 0xff},
+		color.RGBA{0xcd, 0xd6, 0xf4, 0xff},
+	}
+
+	// a 1x export has one pixel per color
+	img := image.NewRGBA(image.Rect(0, 0, len(expected), 1))
+	for x, c := range expected {
+		img.Set(x, 0, c)
+	}
+
+	actual, _, err := ReadSwatchPalette(img, parsepalette.DefaultMaxColors)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("Expected palette %v, got %v", expected, actual)
+	}
+}
+
+func Test_ReadSwatchPaletteGrid(t *testing.T) {
+	expected := color.Palette{
+		color.RGBA{0xdc, 0x8a, 0x78, 0xff},
+		color.RGBA{0xea, 0x76, 0xcb, 0xff},
+		color.RGBA{0x88, 0x39, 0xef, 0xff},
+		color.RGBA{0x40, 0xa0, 0x2b, 0xff},
+		color.RGBA{0x04, 0xa5, 0xe5, 0xff},
+		color.RGBA{0x4c, 0x4f, 0x69, 0xff},
+	}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+
+	// a card with a white background, 3x2 swatches of 20x20 pixels with a 4
+	// pixel gap and a small black label inside every swatch
+	img := image.NewRGBA(image.Rect(0, 0, 76, 52))
+	draw.Draw(img, img.Bounds(), &image.Uniform{white}, image.Point{}, draw.Src)
+	for i, c := range expected {
+		x, y := 4+(i%3)*24, 4+(i/3)*24
+		draw.Draw(img, image.Rect(x, y, x+20, y+20), &image.Uniform{c}, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x+2, y+14, x+8, y+17), &image.Uniform{black}, image.Point{}, draw.Src)
+	}
+
+	actual, _, err := ReadSwatchPalette(img, parsepalette.DefaultMaxColors)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("Expected palette %v, got %v", expected, actual)
+	}
+}
+
+func Test_ReadSwatchPaletteNearIdenticalNeighbours(t *testing.T) {
+	// a ramp of neighbouring swatches only a few steps apart, which must
+	// neither merge nor be averaged
+	expected := color.Palette{
+		color.RGBA{0x0a, 0x0a, 0x0a, 0xff},
+		color.RGBA{0x10, 0x10, 0x10, 0xff},
+		color.RGBA{0x16, 0x16, 0x16, 0xff},
+		color.RGBA{0xc8, 0x32, 0x32, 0xff},
+		color.RGBA{0xce, 0x36, 0x36, 0xff},
+	}
+
+	for _, scale := range []int{1, 8} {
+		img := image.NewRGBA(image.Rect(0, 0, len(expected)*scale, scale))
+		for i, c := range expected {
+			draw.Draw(img, image.Rect(i*scale, 0, (i+1)*scale, scale), &image.Uniform{c}, image.Point{}, draw.Src)
+		}
+
+		actual, _, err := ReadSwatchPalette(img, parsepalette.DefaultMaxColors)
+		if err != nil {
+			t.Fatalf("Expected no error for scale %v, got error: %v", scale, err)
+		}
+		if !slices.Equal(expected, actual) {
+			t.Errorf("Expected palette %v for scale %v, got %v", expected, scale, actual)
+		}
+	}
+}
+
+func Test_ReadSwatchPaletteRepeatedColors(t *testing.T) {
+	a := color.RGBA{0x20, 0x30, 0x40, 0xff}
+	b := color.RGBA{0xe0, 0xd0, 0xc0, 0xff}
+	c := color.RGBA{0x80, 0x10, 0x10, 0xff}
+
+	// a 3x2 grid of 4 pixel cells, with the first color repeated in the
+	// whole second row, forming one area with the first cell
+	img := image.NewRGBA(image.Rect(0, 0, 12, 8))
+	for i, swatch := range []color.RGBA{a, b, c, a, a, a} {
+		x, y := (i%3)*4, (i/3)*4
+		draw.Draw(img, image.Rect(x, y, x+4, y+4), &image.Uniform{swatch}, image.Point{}, draw.Src)
+	}
+
+	actual, repeated, err := ReadSwatchPalette(img, parsepalette.DefaultMaxColors)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	expected := color.Palette{a, b, c}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("Expected palette %v, got %v", expected, actual)
+	}
+	if repeated != 3 {
+		t.Errorf("Expected 3 repeated swatches, got %v", repeated)
+	}
+}
+
+func Test_ReadSwatchPaletteNoSwatches(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{0x80, 0x80, 0x80, 0xff}}, image.Point{}, draw.Src)
+
+	if _, _, err := ReadSwatchPalette(img, parsepalette.DefaultMaxColors); err == nil {
+		t.Errorf("Expected error for image without swatches, got none")
+	}
+}
+
+func Test_ReadSwatchPaletteCard(t *testing.T) {
+	var entries []parsepalette.Entry
+	var expected color.Palette
+	for i := 0; i < 10; i++ {
+		c := color.RGBA{uint8(i * 25), uint8(255 - i*20), 0x80, 0xff}
+		entries = append(entries, parsepalette.Entry{Color: c, Weight: 0.1})
+		expected = append(expected, c)
+	}
+
+	cardPath := filepath.Join(t.TempDir(), "card.png")
+	if err := parsepalette.SaveDocument(cardPath, &parsepalette.Document{Entries: entries}, parsepalette.SaveOptions{CoverageBars: true}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	card, err := GetDecodedImage(cardPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	actual, _, err := ReadSwatchPalette(card, parsepalette.DefaultMaxColors)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if !slices.Equal(expected, actual) {
+		t.Errorf("Expected palette %v, got %v", expected, actual)
+	}
+}
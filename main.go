@@ -3,10 +3,17 @@ package main
 import (
 	"flag"
 	"fmt"
+	"image"
+	"image/color"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/VannRR/color-schemorator/colorspace"
 	"github.com/VannRR/color-schemorator/imagehandling"
+	"github.com/VannRR/color-schemorator/palettetools"
 	"github.com/VannRR/color-schemorator/parsepalette"
 	"github.com/VannRR/color-schemorator/utility"
 )
@@ -14,19 +21,71 @@ import (
 const version = "1.1.2"
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "palettes":
+			runPalettesCommand(os.Args[2:])
+			return
+		case "palette":
+			runPaletteCommand(os.Args[2:])
+			return
+		case "image":
+			runImageCommand(os.Args[2:])
+			return
+		}
+	}
+
 	versionFlag := flag.Bool("v", false, "Display the version of the Color Schemorator tool")
 	helpFlag := flag.Bool("h", false, "Display help message")
 	mode := flag.String("m", "", "Mode of operation: 'generate' or 'extract'")
 	paletteInput := flag.String("p", "",
-		"Path to the plain text file containing hex color codes, one per line (required for 'generate' mode)")
-	imageInput := flag.String("i", "",
-		"Path to the input image file (supported formats: jpg, jpeg, png)")
+		"Path to the plain text file containing hex color codes, one per line, "+
+			"to a swatch image or 'builtin:<name>' (required for 'generate' mode)")
+	var imageInputs stringListFlag
+	flag.Var(&imageInputs, "i",
+		"Path to the input image file (supported formats: jpg, jpeg, png), "+
+			"can be repeated or be a directory in 'extract' mode")
 	imageOutput := flag.String("o", "",
 		"Path to the output image file (supported formats: jpg, jpeg, png) (required for 'generate' mode)")
-	paletteOutput := flag.String("P", "", "Path to the output palette file (required for 'extract' mode)")
+	paletteOutput := flag.String("P", "",
+		"Path to the output palette file, its extension selects the format (required for 'extract' mode)")
+	paletteName := flag.String("name", "",
+		"Name of the extracted palette, saved in '.json' palette output and naming the '.go' output variable")
+	goPackage := flag.String("package", parsepalette.DefaultGoPackage, "Package name of '.go' palette output")
+	roles := flag.String("roles", "",
+		"Comma separated role names of the first extracted colors, used as variable names in 'extract' mode")
+	coverageBars := flag.Bool("coverage", false, "Draw coverage bars on swatch card output in 'extract' mode")
+	alphaThreshold := flag.Uint("alpha", uint(imagehandling.DefaultAlphaThreshold),
+		"Minimum alpha (0-255) of a pixel to be counted in 'extract' mode")
+	region := flag.String("region", "",
+		"Only extract colors from the rectangle 'x,y,w,h' of the input image")
+	maskInput := flag.String("mask", "",
+		"Path to a grayscale mask image, only pixels where it is bright are extracted")
+	weights := flag.String("weights", "",
+		"Comma separated weights of the -i inputs in 'extract' mode (default all 1)")
+	sortMode := flag.String("sort", string(palettetools.SortFrequency),
+		"Order of the extracted palette: 'frequency', 'lightness', 'hue' or 'spectral'")
+	maxColors := flag.Int("max-colors", parsepalette.DefaultMaxColors,
+		"Most colors in a palette, 0 for no limit (default no limit for jpg output in 'generate' mode)")
+	variant := flag.String("variant", "",
+		"Use the 'light' or 'dark' variant of the palette in 'generate' mode, mirroring its lightness if needed")
+	cvd := flag.String("cvd", "",
+		"Keep the colors of the generated image apart with 'protanopia', 'deuteranopia' or 'tritanopia'")
+	cvdMethod := flag.String("cvd-method", string(colorspace.CVDMachado),
+		"Simulation method of -cvd: 'machado' or 'brettel'")
+	cvdSeverity := flag.Float64("cvd-severity", 1, "Severity of the -cvd deficiency, from 0 (normal vision) to 1")
+	contrast := flag.String("contrast", "",
+		"Make role pairs legible in 'extract' mode, measuring contrast with 'wcag' or 'apca'")
+	minContrast := flag.Float64("min-contrast", 0,
+		"Contrast -contrast makes role pairs reach (default 4.5 for wcag, 60 for apca)")
 
 	flag.Parse()
 
+	if *maxColors < 0 || *minContrast < 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+
 	if *versionFlag {
 		printVersionMessage()
 		os.Exit(0)
@@ -37,21 +96,46 @@ func main() {
 
 	switch *mode {
 	case "generate":
-		if *paletteInput == "" || *imageInput == "" || *imageOutput == "" {
+		if *paletteInput == "" || len(imageInputs) != 1 || *imageOutput == "" {
 			printInvalidArgsMessage()
 			os.Exit(1)
 		}
+		paletteLimit := *maxColors
+		if !isFlagSet("max-colors") && isTruecolorOutput(*imageOutput) {
+			paletteLimit = 0
+		}
+		var sim colorspace.CVDSimulation
+		if *cvd != "" {
+			sim = parseCVDSimulation(*cvd, *cvdMethod, *cvdSeverity)
+		} else if isFlagSet("cvd-method") || isFlagSet("cvd-severity") {
+			fmt.Fprintln(os.Stderr, "-cvd-method and -cvd-severity need a -cvd deficiency")
+			os.Exit(1)
+		}
 		start := time.Now()
-		generate(*paletteInput, *imageInput, *imageOutput)
+		generate(*paletteInput, imageInputs[0], *imageOutput, paletteLimit, *variant, sim)
 		fmt.Println("Image generated successfully in", time.Since(start))
 
 	case "extract":
-		if *imageInput == "" || *paletteOutput == "" {
+		if len(imageInputs) == 0 || *paletteOutput == "" {
 			printInvalidArgsMessage()
 			os.Exit(1)
 		}
+		if *alphaThreshold > 255 {
+			fmt.Fprintln(os.Stderr, "-alpha must be between 0 and 255")
+			os.Exit(1)
+		}
+		validateGoPackage(*goPackage)
+		validatePaletteOutput(*paletteOutput)
+		validateContrastFlags(*contrast, *minContrast)
+		if *contrast != "" && *roles == "" {
+			fmt.Fprintln(os.Stderr, "-contrast needs -roles to know which colors are paired")
+			os.Exit(1)
+		}
+		opts := extractOptions(uint8(*alphaThreshold), *region, *maskInput)
+		opts.MaxColors = *maxColors
 		start := time.Now()
-		extract(*imageInput, *paletteOutput)
+		extract(imageInputs, parseWeights(*weights, len(imageInputs)), *paletteOutput, *paletteName, *goPackage, *sortMode, *roles,
+			*contrast, *minContrast, *coverageBars, opts)
 		fmt.Println("Palette extracted successfully in", time.Since(start))
 
 	default:
@@ -60,8 +144,13 @@ func main() {
 	}
 }
 
-// generate creates a new image from the input image by replacing its palette
-func generate(paletteInputPath, imgInputPath, imgOutputPath string) {
+// generate creates a new image from the input image by replacing its palette,
+// with a palette of more than 256 colors the image is saved in truecolor.
+// Palettes of more than maxColors colors are refused unless it is 0. A
+// non-empty variant selects the light or dark variant of the palette. With a
+// color vision deficiency the palette colors are reassigned to stay apart with it.
+func generate(paletteInputPath, imgInputPath, imgOutputPath string, maxColors int, variant string,
+	sim colorspace.CVDSimulation) {
 	if err := utility.ValidateExtension(imgInputPath, "input image"); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -71,11 +160,20 @@ func generate(paletteInputPath, imgInputPath, imgOutputPath string) {
 		os.Exit(1)
 	}
 
-	palette, err := parsepalette.ParsePalette(paletteInputPath)
+	doc, err := loadDocument(paletteInputPath, maxColors)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if variant != "" {
+		theme, err := palettetools.ParseTheme(variant)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		doc.Entries = palettetools.ThemeVariant(doc.Entries, theme)
+	}
+	palette := doc.Palette()
 
 	oldImg, err := imagehandling.GetDecodedImage(imgInputPath)
 	if err != nil {
@@ -83,7 +181,16 @@ func generate(paletteInputPath, imgInputPath, imgOutputPath string) {
 		os.Exit(1)
 	}
 
-	newImg := imagehandling.GenerateNewImg(oldImg, palette)
+	var newImg image.Image
+	if len(palette) > imagehandling.MaxPalettedColors {
+		newImg = imagehandling.GenerateTruecolorImg(oldImg, palette)
+	} else {
+		newImg = imagehandling.GenerateNewImg(oldImg, palette)
+	}
+	if sim.Deficiency != "" {
+		remap := palettetools.DistinguishableRemap(imagehandling.PaletteUsage(oldImg, palette), palette, sim)
+		newImg = imagehandling.RemapImgColors(newImg, remap)
+	}
 
 	if err = imagehandling.SaveNewImg(imgOutputPath, newImg); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -91,28 +198,244 @@ func generate(paletteInputPath, imgInputPath, imgOutputPath string) {
 	}
 }
 
-// extract extracts the most common colors from an image, saving them to a plain
-// text file of hex color codes
-func extract(imgInputPath, paletteOutputPath string) {
-	if err := utility.ValidateExtension(imgInputPath, "input image"); err != nil {
+// loadDocument reads a palette with its metadata from a palette file, or from
+// the swatch cells of an image when the path has an image extension, refusing
+// palettes of more than maxColors colors unless it is 0. Palettes read from
+// swatch images only have colors
+func loadDocument(paletteInputPath string, maxColors int) (*parsepalette.Document, error) {
+	return loadDocumentWithOptions(paletteInputPath, parsepalette.ParseOptions{MaxColors: maxColors})
+}
+
+// loadDocumentWithOptions reads a palette like loadDocument, with the color
+// limit and handling of duplicates given by the options
+func loadDocumentWithOptions(paletteInputPath string, opts parsepalette.ParseOptions) (*parsepalette.Document, error) {
+	if utility.ValidateExtension(paletteInputPath, "input palette") == nil {
+		swatchImg, err := imagehandling.GetDecodedImage(paletteInputPath)
+		if err != nil {
+			return nil, err
+		}
+		palette, repeated, err := imagehandling.ReadSwatchPalette(swatchImg, opts.MaxColors)
+		if err != nil {
+			return nil, err
+		}
+		if repeated > 0 {
+			fmt.Fprintf(os.Stderr, "warning: %v: left out %v swatches repeating an earlier color\n",
+				paletteInputPath, repeated)
+		}
+		doc := &parsepalette.Document{Source: paletteInputPath}
+		for _, c := range palette {
+			doc.Entries = append(doc.Entries, parsepalette.Entry{Color: c.(color.RGBA)})
+		}
+		return doc, nil
+	}
+
+	return parsepalette.ParseDocumentWithOptions(paletteInputPath, opts)
+}
+
+// extract extracts the most common colors from one or more images, saving them
+// to a plain text file of hex color codes annotated with how much of the images
+// each covers. Directories are expanded to the images they contain, which split
+// the weight given for the directory evenly. A contrast method makes the role pairs of
+// the palette legible.
+func extract(imgInputPaths []string, weights []float64, paletteOutputPath, paletteName, goPackage,
+	sortModeName, rolesString, contrastMethod string, minContrast float64, coverageBars bool,
+	opts imagehandling.ExtractOptions) {
+	sortMode, err := palettetools.ParseSortMode(sortModeName)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	inputImg, err := imagehandling.GetDecodedImage(imgInputPath)
+	inputs, err := expandImageInputs(imgInputPaths, weights)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	palette := imagehandling.ExtractPalette(inputImg)
+	// every image is counted as soon as it is decoded, keeping only one
+	// decoded image in memory at a time
+	coverage := imagehandling.NewPaletteCoverage(opts)
+	for _, input := range inputs {
+		inputImg, err := imagehandling.GetDecodedImage(input.path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if err := opts.Validate(inputImg); err != nil {
+			fmt.Fprintf(os.Stderr, "%v: %v\n", input.path, err)
+			os.Exit(1)
+		}
+
+		coverage.Add(inputImg, input.weight)
+	}
+
+	entries := palettetools.SortEntries(coverage.Entries(), sortMode)
+
+	roles, err := parseRoles(rolesString, len(entries))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for i, role := range roles {
+		entries[i].Role = role
+	}
+	if contrastMethod != "" {
+		entries = enforceContrast(entries, contrastMethod, minContrast)
+	}
 
-	if err := parsepalette.SaveNewPalette(paletteOutputPath, palette); err != nil {
+	doc := &parsepalette.Document{
+		Name:    paletteName,
+		Source:  strings.Join(imgInputPaths, ", "),
+		Entries: entries,
+	}
+	saveOpts := parsepalette.SaveOptions{CoverageBars: coverageBars, GoPackage: goPackage}
+	if err := parsepalette.SaveDocument(paletteOutputPath, doc, saveOpts); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// weightedPath is an input image of extract mode with its weight
+type weightedPath struct {
+	path   string
+	weight float64
+}
+
+// expandImageInputs expands the directories among the extract mode inputs to
+// the images they contain, splitting the weight of a directory evenly between
+// its images so that a directory counts as much as a single image of the
+// same weight
+func expandImageInputs(imgInputPaths []string, weights []float64) ([]weightedPath, error) {
+	var inputs []weightedPath
+	for i, inputPath := range imgInputPaths {
+		paths := []string{inputPath}
+		if info, err := os.Stat(inputPath); err == nil && info.IsDir() {
+			paths, err = utility.ListImageFiles(inputPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, path := range paths {
+			if err := utility.ValidateExtension(path, "input image"); err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, weightedPath{path: path, weight: weights[i] / float64(len(paths))})
+		}
+	}
+
+	return inputs, nil
+}
+
+// parseRoles parses the comma separated role names given to the first
+// palette colors in extract mode
+func parseRoles(rolesString string, count int) ([]string, error) {
+	if rolesString == "" {
+		return nil, nil
+	}
+
+	roles := strings.Split(rolesString, ",")
+	if len(roles) > count {
+		return nil, fmt.Errorf("got %v roles for a palette of %v colors", len(roles), count)
+	}
+	seenRoles := make(map[string]struct{})
+	for i, role := range roles {
+		roles[i] = strings.TrimSpace(role)
+		if err := parsepalette.ValidateRole(roles[i]); err != nil {
+			return nil, err
+		}
+		if _, exists := seenRoles[roles[i]]; exists {
+			return nil, fmt.Errorf("duplicate role name '%v'", roles[i])
+		}
+		seenRoles[roles[i]] = struct{}{}
+	}
+
+	return roles, nil
+}
+
+// parseWeights parses the comma separated image weights of extract mode,
+// there must be one positive weight per input
+func parseWeights(weightsString string, count int) []float64 {
+	weights := make([]float64, count)
+	if weightsString == "" {
+		for i := range weights {
+			weights[i] = 1
+		}
+		return weights
+	}
+
+	parts := strings.Split(weightsString, ",")
+	if len(parts) != count {
+		fmt.Fprintf(os.Stderr, "expected %v weights, one per input, got %v\n", count, len(parts))
+		os.Exit(1)
+	}
+	for i, part := range parts {
+		weight, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || weight <= 0 {
+			fmt.Fprintf(os.Stderr, "invalid weight '%v'\n", part)
+			os.Exit(1)
+		}
+		weights[i] = weight
+	}
+
+	return weights
+}
+
+// extractOptions builds the pixel selection options for extract mode,
+// loading the mask image if one is given
+func extractOptions(alphaThreshold uint8, regionString, maskPath string) imagehandling.ExtractOptions {
+	opts := imagehandling.ExtractOptions{AlphaThreshold: alphaThreshold}
+
+	if regionString != "" {
+		region, err := imagehandling.ParseRegion(regionString)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts.Region = region
+	}
+
+	if maskPath != "" {
+		mask, err := imagehandling.GetDecodedImage(maskPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts.Mask = mask
+	}
+
+	return opts
+}
+
+// isFlagSet reports whether the flag was given on the command line
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// isTruecolorOutput reports whether the image output format cannot store a
+// palette, so that generate mode has no reason to limit the palette size
+func isTruecolorOutput(imgOutputPath string) bool {
+	ext := strings.ToLower(filepath.Ext(imgOutputPath))
+	return ext == ".jpg" || ext == ".jpeg"
+}
+
+// stringListFlag is a flag that can be given multiple times
+type stringListFlag []string
+
+func (s *stringListFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringListFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func printVersionMessage() {
 	fmt.Printf("Color Schemorator version %v\n", version)
 	fmt.Println("Color Schemorator is a tool that adjusts the color palette of an image based")
@@ -124,34 +447,202 @@ func printVersionMessage() {
 func printHelpMessage() {
 	fmt.Println("Usage:")
 	fmt.Println("  csor -m generate -p <palettePath> -i <imgInputPath> -o <imgOutputPath>")
-	fmt.Println("  csor -m extract -i <imgInputPath> -P <paletteOutputPath>")
+	fmt.Println("  csor -m extract -i <imgInputPath> -P <paletteOutputPath> [options]")
+	fmt.Println("  csor palettes list")
+	fmt.Println("  csor palettes show <name> [-P <paletteOutputPath>]")
+	fmt.Println("  csor palette harmony -seed <hex> [-scheme <scheme>] [-n <count>] [options]")
+	fmt.Println("  csor palette adjust -p <palettePath> [-P <paletteOutputPath>] [adjustments]")
+	fmt.Println("  csor palette variant -p <palettePath> [-theme light|dark] [-P <paletteOutputPath>]")
+	fmt.Println("  csor palette material -i <imgInputPath> | -seed <hex> [-theme light|dark] [options]")
+	fmt.Println("  csor palette lint -p <palettePath> [-contrast wcag|apca] [thresholds]")
+	fmt.Println("  csor palette simulate -p <palettePath> -cvd <deficiency> [options]")
+	fmt.Println("  csor palette fmt [-w] [-l] [-sort <mode>] <palettePath>...")
+	fmt.Println("  csor image simulate -i <imgInputPath> -o <imgOutputPath> -cvd <deficiency> [options]")
+	fmt.Println("  csor image daltonize -i <imgInputPath> -o <imgOutputPath> -cvd <deficiency> [options]")
 	fmt.Println("  csor -v")
 	fmt.Println("  csor -h")
 	fmt.Println()
 	fmt.Println("Description:")
 	fmt.Println("  Color Schemorator modifies an image's color palette based on a given list")
 	fmt.Println("  of hex color codes (file can have '//' comments) or extracts the color")
-	fmt.Println("  palette from an image.")
+	fmt.Println("  palette from an image. Palette files can compose other palettes with")
+	fmt.Println("  '@include <path>' and drop colors with '@exclude <hex>' lines, and a")
+	fmt.Println("  line like '#1e1e2e -> #cdd6f4 : 8 oklch' expands into a gradient.")
 	fmt.Println()
 	fmt.Println("  - Generate mode: Creates a new image by replacing its colors with the")
 	fmt.Println("    closest matches from the specified palette.")
 	fmt.Println("  - Extract mode: Extracts the color palette from an image (in order of")
-	fmt.Println("    occurrence) and saves it to a file.")
+	fmt.Println("    occurrence, or the order given by -sort) and saves it to a file, with")
+	fmt.Println("    each color's coverage, rgb() and hsl() values as a '//' comment.")
+	fmt.Println("  - palettes list: Lists the built-in palettes (Catppuccin, Nord, Gruvbox,")
+	fmt.Println("    Dracula, Solarized, Tokyo Night, Rose Pine, PICO-8, Game Boy, ...).")
+	fmt.Println("  - palettes show: Prints a built-in palette with its color names, or saves")
+	fmt.Println("    it with -P in any palette format.")
+	fmt.Println("  - palette harmony: Generates a palette of -n colors (default 6) around the")
+	fmt.Println("    -seed color in OKLCh, rotating its hue by the -scheme ('complementary',")
+	fmt.Println("    'analogous', 'triadic' (default), 'split' or 'tetradic') with lighter")
+	fmt.Println("    and darker tones of every hue. It is printed, or saved with -P, and")
+	fmt.Println("    takes the -name, -package and -sort options.")
+	fmt.Println("  - palette adjust: Changes every color of the -p palette, read and written")
+	fmt.Println("    in any supported format (printed without -P). Adjustments are applied")
+	fmt.Println("    in this order:")
+	fmt.Println("      -invert            replace colors by their negative")
+	fmt.Println("      -lightness <d>     add d to the OKLCh lightness (0-1)")
+	fmt.Println("      -chroma <r>        change chroma by r, 0.2 is 20% more, -1 is gray")
+	fmt.Println("      -hue <deg>         rotate the OKLCh hue")
+	fmt.Println("      -temperature <t>   warm (up to 1) or cool (down to -1) colors")
+	fmt.Println("      -mix <hex>         mix toward a color by -mix-amount (default 0.5)")
+	fmt.Println("                         in -mix-space ('oklab' (default), 'oklch', 'srgb',")
+	fmt.Println("                         'linear' or 'hsl')")
+	fmt.Println("      -contrast <method> make role pairs legible like -contrast in 'extract'")
+	fmt.Println("                         mode, after the other adjustments")
+	fmt.Println("  - palette variant: Turns a dark -p palette into its light counterpart, or")
+	fmt.Println("    a light one into its dark counterpart (or the -theme given). Lightness")
+	fmt.Println("    is mirrored so that each color's contrast against black becomes its")
+	fmt.Println("    contrast against white, keeping hues and the contrast between colors.")
+	fmt.Println("    A palette's theme is judged by its 'background' role if it has one.")
+	fmt.Println("  - palette material: Derives a Material 3 color scheme from the -seed color,")
+	fmt.Println("    or from the dominant color of the -i image. The primary, secondary,")
+	fmt.Println("    tertiary, neutral and error tonal palettes are built in the HCT color")
+	fmt.Println("    space, and the -theme ('light' (default) or 'dark') scheme's roles")
+	fmt.Println("    ('primary', 'on-primary', 'primary-container', 'surface', 'outline',")
+	fmt.Println("    ...) are taken from them. -tonal outputs the palettes' tones instead,")
+	fmt.Println("    with roles like 'primary-40'. It takes the -P, -name and -package")
+	fmt.Println("    options.")
+	fmt.Println("  - palette lint: Reports problems of the -p palette and exits with status 1")
+	fmt.Println("    if there are any:")
+	fmt.Println("      near-duplicate     pairs of colors closer than -min-delta (default")
+	fmt.Println("                         0.02) in OKLab, or the same color listed twice,")
+	fmt.Println("                         unless both colors have a role")
+	fmt.Println("      extreme            colors without a role almost black or white (but")
+	fmt.Println("                         not exactly)")
+	fmt.Println("      spread             OKLab lightness from the darkest to the lightest")
+	fmt.Println("                         color below -min-spread (default 0.3)")
+	fmt.Println("      contrast           'foreground' and accent roles (like 'accent-2') on")
+	fmt.Println("                         'background', and roles like 'on-primary' on")
+	fmt.Println("                         'primary', below -min-contrast in the -contrast")
+	fmt.Println("                         method: 'wcag' (default, ratio 4.5) or 'apca'")
+	fmt.Println("                         (Lc 60)")
+	fmt.Println("  - palette simulate: Shows how the colors of the -p palette look with a")
+	fmt.Println("    color vision deficiency, printed or saved with -P (a '.png' preview")
+	fmt.Println("    card, or any palette format).")
+	fmt.Println("  - palette fmt: Rewrites text palettes in a canonical form, printed, or")
+	fmt.Println("    saved back to their files with -w (-l lists the files that change):")
+	fmt.Println("    uppercase '#RRGGBB' colors, no indentation or trailing spaces, single")
+	fmt.Println("    spaces in directives and ramps, trailing comments aligned, no repeated")
+	fmt.Println("    blank lines, and colors repeating an earlier color or ramp removed")
+	fmt.Println("    (keeping their comments), colors of included files are not checked.")
+	fmt.Println("    -sort ('lightness', 'hue' or 'spectral') sorts each run of color")
+	fmt.Println("    lines, comment and blank lines stay in place.")
+	fmt.Println("  - image simulate: Saves the -i image as it looks with a color vision")
+	fmt.Println("    deficiency to -o, such as an image made in 'generate' mode.")
+	fmt.Println("  - image daltonize: Saves the -i image to -o with its colors corrected so")
+	fmt.Println("    that more of them can be told apart with a color vision deficiency.")
+	fmt.Println("    The simulate and daltonize commands take:")
+	fmt.Println("      -cvd <deficiency>  'protanopia', 'deuteranopia' or 'tritanopia'")
+	fmt.Println("      -method <method>   'machado' (default, Machado et al. 2009) or")
+	fmt.Println("                         'brettel' (Brettel et al. 1997, better for")
+	fmt.Println("                         tritanopia)")
+	fmt.Println("      -severity <s>      from 0 (normal vision) to 1 (default)")
 	fmt.Println()
 	fmt.Println("Arguments:")
 	fmt.Println("  -m   Mode of operation: 'generate' or 'extract'.")
 	fmt.Println("  -p   Path to the plain text file containing hex color codes, one per line")
-	fmt.Println("       (required for 'generate' mode).")
+	fmt.Println("       (required for 'generate' mode). A '.json' palette can be used too.")
+	fmt.Println("       A swatch image (jpg, jpeg, png) of color cells in a strip or grid")
+	fmt.Println("       can be used instead, its cells are read in reading order with their")
+	fmt.Println("       exact colors, leaving out cells that repeat an earlier color.")
+	fmt.Println("       'builtin:<name>' selects a built-in palette, such as 'builtin:nord'.")
 	fmt.Println("  -i   Path to the input image file (supported formats: jpg, jpeg, png).")
+	fmt.Println("       In 'extract' mode it can be repeated or be a directory of images to")
+	fmt.Println("       extract one palette across all of them.")
 	fmt.Println("  -o   Path to the output image file (supported formats: jpg, jpeg, png)")
 	fmt.Println("       (required for 'generate' mode).")
 	fmt.Println("  -P   Path to the output palette file (required for 'extract' mode).")
+	fmt.Println("       The extension selects the format: a '.json' palette with metadata")
+	fmt.Println("       (name, source image, color names, roles and weights), '.css' custom")
+	fmt.Println("       properties, '.scss' or '.less' variables, a '.png' or '.svg' preview")
+	fmt.Println("       card of labeled swatches, '.tokens' or '.tokens.json' W3C design")
+	fmt.Println("       tokens, a '.js' Tailwind config, a '.go' file declaring a")
+	fmt.Println("       color.Palette variable, or else a plain text file of hex color codes.")
+	fmt.Println("       Other image extensions, such as '.jpg' or '.gif', are refused.")
+	fmt.Println("       Design tokens and Tailwind colors include a 50-950 tonal scale.")
+	fmt.Println("  -name")
+	fmt.Println("       Name of the extracted palette, saved in '.json' palette output and")
+	fmt.Println("       naming the variable of '.go' output (default 'Palette').")
+	fmt.Println("  -package")
+	fmt.Println("       Package name of '.go' palette output (default 'palette').")
+	fmt.Println("  -roles")
+	fmt.Println("       Comma separated role names of the first extracted colors, in palette")
+	fmt.Println("       order, used in variable names (e.g. 'background,foreground').")
+	fmt.Println("  -coverage")
+	fmt.Println("       Draw a bar below each swatch of a swatch card showing its coverage")
+	fmt.Println("       relative to the most common color.")
+	fmt.Println("  -alpha")
+	fmt.Println("       Minimum alpha (0-255) of a pixel to be counted in 'extract' mode,")
+	fmt.Println("       fully transparent pixels are never counted (default 128).")
+	fmt.Println("  -region")
+	fmt.Println("       Only extract colors from the rectangle 'x,y,w,h' of the input image.")
+	fmt.Println("  -mask")
+	fmt.Println("       Path to a grayscale mask image the size of the input image, only")
+	fmt.Println("       pixels where the mask is at least half bright are extracted.")
+	fmt.Println("  -weights")
+	fmt.Println("       Comma separated weights of the -i inputs in 'extract' mode, each image")
+	fmt.Println("       contributes in proportion to its weight (default all 1). The images")
+	fmt.Println("       of a directory split its weight evenly.")
+	fmt.Println("  -sort")
+	fmt.Println("       Order of the extracted palette: 'frequency' (default), 'lightness',")
+	fmt.Println("       'hue' or 'spectral' (smooth perceptual path between neighbours).")
+	fmt.Println("  -max-colors")
+	fmt.Println("       Most colors in a palette, 0 for no limit (default 256). In 'extract'")
+	fmt.Println("       mode the most common colors are kept, in 'generate' mode larger")
+	fmt.Println("       palettes are refused. jpg output in 'generate' mode has no limit by")
+	fmt.Println("       default, and palettes of more than 256 colors are saved truecolor.")
+	fmt.Println("  -variant")
+	fmt.Println("       Use the 'light' or 'dark' variant of the palette in 'generate' mode,")
+	fmt.Println("       mirrored like 'palette variant' when the palette is of the other theme.")
+	fmt.Println("  -cvd")
+	fmt.Println("       In 'generate' mode, reassign palette colors so that colors of the image")
+	fmt.Println("       that can be told apart stay apart with 'protanopia', 'deuteranopia' or")
+	fmt.Println("       'tritanopia', moving them to the closest palette colors that do. It")
+	fmt.Println("       takes -cvd-method and -cvd-severity, the -method and -severity of")
+	fmt.Println("       'image simulate'.")
+	fmt.Println("  -contrast")
+	fmt.Println("       Make the role pairs checked by 'palette lint' (such as 'foreground' on")
+	fmt.Println("       'background') legible in 'extract' mode, measuring contrast with")
+	fmt.Println("       'wcag' or 'apca'. The lightness of each pair's foreground is changed")
+	fmt.Println("       as little as needed to reach -min-contrast, keeping its hue. It needs")
+	fmt.Println("       -roles to name the colors that are paired.")
+	fmt.Println("  -min-contrast")
+	fmt.Println("       Contrast -contrast makes role pairs reach (default 4.5 for 'wcag', the")
+	fmt.Println("       WCAG AA ratio for text, and Lc 60 for 'apca'), only with -contrast.")
 	fmt.Println("  -v   Display the version of the Color Schemorator tool.")
 	fmt.Println("  -h   Display this help message.")
 	fmt.Println()
 	fmt.Println("Example:")
 	fmt.Println("  csor -m generate -p colors.txt -i original-image.jpg -o new-image.jpg")
+	fmt.Println("  csor -m generate -p builtin:nord -i original-image.jpg -o new-image.jpg")
+	fmt.Println("  csor -m generate -p night.txt -variant light -i wallpaper.jpg -o day.jpg")
+	fmt.Println("  csor -m generate -p builtin:pico-8 -cvd deuteranopia -i chart.png -o chart-pico-8.png")
 	fmt.Println("  csor -m extract -i original-image.jpg -P palette.txt")
+	fmt.Println("  csor -m extract -i original-image.jpg -P palette.txt -sort spectral")
+	fmt.Println("  csor -m extract -i original-image.jpg -P palette.txt -region 100,50,400,300")
+	fmt.Println("  csor -m extract -i shot-1.png -i shot-2.png -weights 2,1 -P palette.txt")
+	fmt.Println("  csor -m extract -i original-image.jpg -P palette.png -coverage")
+	fmt.Println("  csor -m extract -i original-image.jpg -P palette.css -roles background,foreground")
+	fmt.Println("  csor -m extract -i terminal.png -P theme.css -roles background,foreground -contrast apca")
+	fmt.Println("  csor -m extract -i brand-image.png -P tailwind.config.js -roles brand,accent")
+	fmt.Println("  csor -m extract -i sprite.png -P sprite_palette.go -name sprite -package assets")
+	fmt.Println("  csor palettes show catppuccin-mocha -P mocha.css")
+	fmt.Println("  csor palette harmony -seed #ea76cb -scheme triadic -n 12 -P harmony.txt")
+	fmt.Println("  csor palette adjust -p builtin:nord -lightness 0.05 -chroma 0.2 -P nord.css")
+	fmt.Println("  csor palette variant -p builtin:catppuccin-mocha -P mocha-light.txt")
+	fmt.Println("  csor palette material -i wallpaper.jpg -theme dark -P theme.css")
+	fmt.Println("  csor palette lint -p builtin:solarized-light -contrast apca")
+	fmt.Println("  csor palette simulate -p builtin:pico-8 -cvd deuteranopia -P pico-8-deutan.png")
+	fmt.Println("  csor palette fmt -w -sort lightness palettes/*.txt")
+	fmt.Println("  csor image simulate -i new-image.png -o new-image-tritan.png -cvd tritanopia -method brettel")
+	fmt.Println("  csor image daltonize -i chart.png -o chart-deutan.png -cvd deuteranopia")
 }
 
 func printInvalidArgsMessage() {
@@ -159,7 +650,18 @@ func printInvalidArgsMessage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  csor -m generate -p <palettePath> -i <imgInputPath> -o <imgOutputPath>")
-	fmt.Println("  csor -m extract -i <imgInputPath> -P <paletteOutputPath>")
+	fmt.Println("  csor -m extract -i <imgInputPath> -P <paletteOutputPath> [options]")
+	fmt.Println("  csor palettes list")
+	fmt.Println("  csor palettes show <name> [-P <paletteOutputPath>]")
+	fmt.Println("  csor palette harmony -seed <hex> [-scheme <scheme>] [-n <count>] [options]")
+	fmt.Println("  csor palette adjust -p <palettePath> [-P <paletteOutputPath>] [adjustments]")
+	fmt.Println("  csor palette variant -p <palettePath> [-theme light|dark] [-P <paletteOutputPath>]")
+	fmt.Println("  csor palette material -i <imgInputPath> | -seed <hex> [-theme light|dark] [options]")
+	fmt.Println("  csor palette lint -p <palettePath> [-contrast wcag|apca] [thresholds]")
+	fmt.Println("  csor palette simulate -p <palettePath> -cvd <deficiency> [options]")
+	fmt.Println("  csor palette fmt [-w] [-l] [-sort <mode>] <palettePath>...")
+	fmt.Println("  csor image simulate -i <imgInputPath> -o <imgOutputPath> -cvd <deficiency> [options]")
+	fmt.Println("  csor image daltonize -i <imgInputPath> -o <imgOutputPath> -cvd <deficiency> [options]")
 	fmt.Println("  csor -v")
 	fmt.Println("  csor -h")
 	fmt.Println()
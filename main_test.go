@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ExpandImageInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.png", "b.png", "c.png", "d.png", "notes.txt"} {
+		// the images are only listed, not decoded
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+	}
+	hero := filepath.Join(t.TempDir(), "hero.png")
+
+	inputs, err := expandImageInputs([]string{dir, hero}, []float64{1, 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(inputs) != 5 {
+		t.Fatalf("Expected 5 inputs, got %v", inputs)
+	}
+
+	// the images of the directory split its weight, together counting as
+	// much as the single image
+	var dirWeight float64
+	for _, input := range inputs[:4] {
+		dirWeight += input.weight
+	}
+	if dirWeight != 1 || inputs[4].path != hero || inputs[4].weight != 1 {
+		t.Errorf("Expected directory and image weight 1, got %v", inputs)
+	}
+}
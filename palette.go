@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/imagehandling"
+	"github.com/VannRR/color-schemorator/palettetools"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// runPaletteCommand runs the 'csor palette <command>' tools that create and
+// edit palettes
+func runPaletteCommand(args []string) {
+	if len(args) == 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "harmony":
+		harmony(args[1:])
+
+	case "adjust":
+		adjust(args[1:])
+
+	case "variant":
+		variant(args[1:])
+
+	case "material":
+		material(args[1:])
+
+	case "lint":
+		lint(args[1:])
+
+	case "simulate":
+		simulatePalette(args[1:])
+
+	case "fmt":
+		formatPalettes(args[1:])
+
+	default:
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+}
+
+// harmony runs 'csor palette harmony', generating a palette around a seed color
+func harmony(args []string) {
+	flags := flag.NewFlagSet("palette harmony", flag.ExitOnError)
+	seedHex := flags.String("seed", "", "Hex color the harmony is built around")
+	schemeName := flags.String("scheme", string(palettetools.HarmonyTriadic),
+		"Harmony scheme: 'complementary', 'analogous', 'triadic', 'split' or 'tetradic'")
+	count := flags.Int("n", 6, "Number of colors to generate")
+	paletteOutput := flags.String("P", "", "Path to the output palette file, its extension selects the format")
+	paletteName := flags.String("name", "", "Name of the palette")
+	goPackage := flags.String("package", parsepalette.DefaultGoPackage, "Package name of '.go' palette output")
+	sortModeName := flags.String("sort", string(palettetools.SortFrequency),
+		"Order of the palette: 'frequency' (as generated), 'lightness', 'hue' or 'spectral'")
+	flags.Parse(args)
+
+	if *seedHex == "" || flags.NArg() > 0 || *count < parsepalette.MinColors || *count > parsepalette.DefaultMaxColors {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	validateGoPackage(*goPackage)
+	validatePaletteOutput(*paletteOutput)
+
+	seed, err := parsepalette.ParseHexColor(*seedHex)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	scheme, err := palettetools.ParseHarmonyScheme(*schemeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	sortMode, err := palettetools.ParseSortMode(*sortModeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	palette, err := palettetools.Harmony(seed, scheme, *count)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	doc := &parsepalette.Document{
+		Name:   *paletteName,
+		Source: fmt.Sprintf("%v harmony of %v", scheme, parsepalette.FormatHexColor(seed)),
+	}
+	for _, c := range palettetools.SortPalette(palette, sortMode) {
+		doc.Entries = append(doc.Entries, parsepalette.Entry{Color: c.(color.RGBA)})
+	}
+	outputDocument(doc, *paletteOutput, parsepalette.SaveOptions{GoPackage: *goPackage})
+}
+
+// adjust runs 'csor palette adjust', changing every color of a palette
+func adjust(args []string) {
+	flags := flag.NewFlagSet("palette adjust", flag.ExitOnError)
+	paletteInput := flags.String("p", "", "Path to the input palette, in any supported format")
+	paletteOutput := flags.String("P", "", "Path to the output palette file, its extension selects the format")
+	goPackage := flags.String("package", parsepalette.DefaultGoPackage, "Package name of '.go' palette output")
+	invert := flags.Bool("invert", false, "Replace colors by their negative")
+	lightness := flags.Float64("lightness", 0, "Amount added to the OKLCh lightness (0-1) of every color")
+	chroma := flags.Float64("chroma", 0, "Relative change of chroma, 0.2 is 20% more saturated, -1 is gray")
+	hue := flags.Float64("hue", 0, "Degrees added to the OKLCh hue of every color")
+	temperature := flags.Float64("temperature", 0, "Warm (up to 1) or cool (down to -1) every color")
+	mixHex := flags.String("mix", "", "Hex color every color is mixed toward")
+	mixAmount := flags.Float64("mix-amount", 0.5, "Amount of the -mix color, from 0 to 1")
+	mixSpace := flags.String("mix-space", string(colorspace.SpaceOKLab),
+		"Color space of -mix: 'oklab', 'oklch', 'srgb', 'linear' or 'hsl'")
+	contrast := flags.String("contrast", "", "Make role pairs legible, measuring contrast with 'wcag' or 'apca'")
+	minContrast := flags.Float64("min-contrast", 0, "Contrast -contrast makes role pairs reach (default 4.5 for wcag, 60 for apca)")
+	flags.Parse(args)
+
+	if *paletteInput == "" || flags.NArg() > 0 || *mixAmount < 0 || *mixAmount > 1 ||
+		*temperature < -1 || *temperature > 1 || *minContrast < 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	validateGoPackage(*goPackage)
+	validatePaletteOutput(*paletteOutput)
+	validateContrastFlags(*contrast, *minContrast)
+
+	adj := palettetools.Adjustment{
+		Invert:      *invert,
+		Lightness:   *lightness,
+		Chroma:      *chroma,
+		Hue:         *hue,
+		Temperature: *temperature,
+	}
+	if *mixHex != "" {
+		mixColor, err := parsepalette.ParseHexColor(*mixHex)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		space, err := colorspace.ParseSpace(*mixSpace)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		adj.MixColor, adj.MixAmount, adj.MixSpace = mixColor, *mixAmount, space
+	}
+
+	doc, err := loadDocument(*paletteInput, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	doc.Entries = palettetools.AdjustEntries(doc.Entries, adj)
+	if *contrast != "" {
+		doc.Entries = enforceContrast(doc.Entries, *contrast, *minContrast)
+	}
+	outputDocument(doc, *paletteOutput, parsepalette.SaveOptions{GoPackage: *goPackage})
+}
+
+// variant runs 'csor palette variant', turning a dark palette into its light
+// counterpart or the other way around
+func variant(args []string) {
+	flags := flag.NewFlagSet("palette variant", flag.ExitOnError)
+	paletteInput := flags.String("p", "", "Path to the input palette, in any supported format")
+	paletteOutput := flags.String("P", "", "Path to the output palette file, its extension selects the format")
+	goPackage := flags.String("package", parsepalette.DefaultGoPackage, "Package name of '.go' palette output")
+	themeName := flags.String("theme", "", "Theme of the variant, 'light' or 'dark' (default the opposite of the input)")
+	flags.Parse(args)
+
+	if *paletteInput == "" || flags.NArg() > 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	validateGoPackage(*goPackage)
+	validatePaletteOutput(*paletteOutput)
+
+	doc, err := loadDocument(*paletteInput, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	theme := palettetools.ThemeLight
+	if palettetools.PaletteTheme(doc.Entries) == palettetools.ThemeLight {
+		theme = palettetools.ThemeDark
+	}
+	if *themeName != "" {
+		if theme, err = palettetools.ParseTheme(*themeName); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	doc.Entries = palettetools.ThemeVariant(doc.Entries, theme)
+	if doc.Name != "" {
+		doc.Name = fmt.Sprintf("%v (%v)", doc.Name, theme)
+	}
+	outputDocument(doc, *paletteOutput, parsepalette.SaveOptions{GoPackage: *goPackage})
+}
+
+// material runs 'csor palette material', deriving a Material 3 color scheme
+// from a seed color or from the dominant color of an image
+func material(args []string) {
+	flags := flag.NewFlagSet("palette material", flag.ExitOnError)
+	imgInput := flags.String("i", "", "Path to the image the seed color is extracted from")
+	seedHex := flags.String("seed", "", "Hex color the scheme is derived from")
+	themeName := flags.String("theme", string(palettetools.ThemeLight), "Theme of the scheme, 'light' or 'dark'")
+	tonal := flags.Bool("tonal", false, "Output the tonal palettes instead of the scheme's color roles")
+	paletteOutput := flags.String("P", "", "Path to the output palette file, its extension selects the format")
+	paletteName := flags.String("name", "", "Name of the palette")
+	goPackage := flags.String("package", parsepalette.DefaultGoPackage, "Package name of '.go' palette output")
+	flags.Parse(args)
+
+	if (*imgInput == "") == (*seedHex == "") || flags.NArg() > 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	validateGoPackage(*goPackage)
+	validatePaletteOutput(*paletteOutput)
+
+	theme, err := palettetools.ParseTheme(*themeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var seed color.RGBA
+	if *seedHex != "" {
+		if seed, err = parsepalette.ParseHexColor(*seedHex); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		img, err := imagehandling.GetDecodedImage(*imgInput)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		seed = palettetools.SeedColor(imagehandling.ExtractPalette(img))
+	}
+
+	core := palettetools.NewCorePalette(seed)
+	doc := &parsepalette.Document{
+		Name:   *paletteName,
+		Source: fmt.Sprintf("Material 3 %v scheme of %v", theme, parsepalette.FormatHexColor(seed)),
+	}
+	if *tonal {
+		doc.Source = fmt.Sprintf("Material 3 tonal palettes of %v", parsepalette.FormatHexColor(seed))
+		doc.Entries = core.TonalEntries()
+	} else {
+		doc.Entries = core.Scheme(theme)
+	}
+	outputDocument(doc, *paletteOutput, parsepalette.SaveOptions{GoPackage: *goPackage})
+}
+
+// lint runs 'csor palette lint', reporting colors of a palette that are hard
+// to tell apart or to read. It exits with status 1 if there are any.
+func lint(args []string) {
+	defaults := palettetools.DefaultLintOptions()
+	flags := flag.NewFlagSet("palette lint", flag.ExitOnError)
+	paletteInput := flags.String("p", "", "Path to the palette, in any supported format")
+	minDeltaE := flags.Float64("min-delta", defaults.MinDeltaE, "OKLab distance below which two colors are near-duplicates")
+	minSpread := flags.Float64("min-spread", defaults.MinLightnessSpread, "Least OKLab lightness between the darkest and lightest color")
+	contrastName := flags.String("contrast", string(defaults.ContrastMethod), "Contrast of role pairs, 'wcag' or 'apca'")
+	minContrast := flags.Float64("min-contrast", 0, "Least contrast of role pairs (default 4.5 for wcag, 60 for apca)")
+	flags.Parse(args)
+
+	if *paletteInput == "" || flags.NArg() > 0 || *minDeltaE < 0 || *minSpread < 0 || *minContrast < 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+
+	opts := defaults
+	opts.MinDeltaE, opts.MinLightnessSpread = *minDeltaE, *minSpread
+	method, target := parseContrast(*contrastName, *minContrast)
+	opts.ContrastMethod, opts.MinContrast = method, target
+
+	// colors the parser leaves out as duplicates are kept to be reported
+	doc, err := loadDocumentWithOptions(*paletteInput, parsepalette.ParseOptions{KeepDuplicates: true})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	issues := palettetools.Lint(doc.Entries, opts)
+	for _, issue := range issues {
+		fmt.Printf("%v: %v\n", *paletteInput, issue)
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
+
+// simulatePalette runs 'csor palette simulate', showing how the colors of a
+// palette look with a color vision deficiency
+func simulatePalette(args []string) {
+	flags := flag.NewFlagSet("palette simulate", flag.ExitOnError)
+	paletteInput := flags.String("p", "", "Path to the input palette, in any supported format")
+	paletteOutput := flags.String("P", "", "Path to the output palette file, its extension selects the format")
+	goPackage := flags.String("package", parsepalette.DefaultGoPackage, "Package name of '.go' palette output")
+	deficiency := flags.String("cvd", "", "Color vision deficiency: 'protanopia', 'deuteranopia' or 'tritanopia'")
+	method := flags.String("method", string(colorspace.CVDMachado), "Simulation method: 'machado' or 'brettel'")
+	severity := flags.Float64("severity", 1, "Severity of the deficiency, from 0 (normal vision) to 1")
+	flags.Parse(args)
+
+	if *paletteInput == "" || *deficiency == "" || flags.NArg() > 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	validateGoPackage(*goPackage)
+	validatePaletteOutput(*paletteOutput)
+	sim := parseCVDSimulation(*deficiency, *method, *severity)
+
+	doc, err := loadDocument(*paletteInput, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for i := range doc.Entries {
+		doc.Entries[i].Color = sim.Simulate(doc.Entries[i].Color)
+	}
+	if doc.Name != "" {
+		doc.Name = fmt.Sprintf("%v (%v)", doc.Name, sim.Deficiency)
+	}
+	outputDocument(doc, *paletteOutput, parsepalette.SaveOptions{GoPackage: *goPackage})
+}
+
+// formatPalettes runs 'csor palette fmt', rewriting text palettes in their
+// canonical form. Formatted palettes are printed unless -w or -l is given, it
+// exits with status 1 if a palette cannot be formatted.
+func formatPalettes(args []string) {
+	flags := flag.NewFlagSet("palette fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "Write the formatted palettes back to their files")
+	list := flags.Bool("l", false, "List the palettes whose formatting differs")
+	sortModeName := flags.String("sort", "", "Sort runs of color lines: 'lightness', 'hue' or 'spectral'")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+	var sortMode palettetools.SortMode
+	if *sortModeName != "" {
+		var err error
+		if sortMode, err = palettetools.ParseSortMode(*sortModeName); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		// text palettes record no frequencies, the order is already the input order
+		if sortMode == palettetools.SortFrequency {
+			fmt.Fprintln(os.Stderr, "invalid sort mode 'frequency' for fmt (expected lightness, hue or spectral)")
+			os.Exit(1)
+		}
+	}
+
+	failed := false
+	for _, path := range flags.Args() {
+		file, err := parsepalette.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+
+		original := file.Bytes()
+		file.Format()
+		if sortMode != "" {
+			file.SortColors(func(palette color.Palette) []int {
+				return palettetools.SortOrder(palette, sortMode)
+			})
+		}
+		formatted := file.Bytes()
+		changed := !bytes.Equal(original, formatted)
+
+		if *list && changed {
+			fmt.Println(path)
+		}
+		if *write && changed {
+			if err := os.WriteFile(path, formatted, 0o644); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+		}
+		if !*list && !*write {
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// validateContrastFlags checks the -contrast and -min-contrast flags before
+// any work is done, a contrast without a method is an error
+func validateContrastFlags(methodName string, minContrast float64) {
+	if methodName == "" {
+		if minContrast != 0 {
+			fmt.Fprintln(os.Stderr, "-min-contrast needs a -contrast method")
+			os.Exit(1)
+		}
+		return
+	}
+	if _, err := palettetools.ParseContrastMethod(methodName); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parseContrast parses a contrast method given on the command line with the
+// contrast to reach, which defaults to the method's default when it is 0
+func parseContrast(methodName string, minContrast float64) (palettetools.ContrastMethod, float64) {
+	method, err := palettetools.ParseContrastMethod(methodName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if minContrast == 0 {
+		minContrast = method.DefaultMinContrast()
+	}
+	return method, minContrast
+}
+
+// validateGoPackage checks the -package flag before any work is done, so
+// that an invalid name fails right away instead of once the palette is saved
+func validateGoPackage(packageName string) {
+	if err := parsepalette.ValidateGoPackage(packageName); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// validatePaletteOutput checks the -P flag before any work is done, an empty
+// path prints the palette instead and is always valid
+func validatePaletteOutput(paletteOutputPath string) {
+	if paletteOutputPath == "" {
+		return
+	}
+	if err := parsepalette.ValidateOutputPath(paletteOutputPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// enforceContrast makes the role pairs of a palette legible, warning about
+// pairs that cannot reach the contrast even in black or white
+func enforceContrast(entries []parsepalette.Entry, methodName string, minContrast float64) []parsepalette.Entry {
+	method, minContrast := parseContrast(methodName, minContrast)
+	enforced, unmet := palettetools.EnforceContrast(entries, method, minContrast)
+	for _, pair := range unmet {
+		foreground, background := enforced[pair.Foreground], enforced[pair.Background]
+		fmt.Fprintf(os.Stderr, "warning: '%v' on '%v' cannot reach %v contrast %v, got %.2f\n",
+			foreground.Role, background.Role, method, minContrast, method.Contrast(foreground.Color, background.Color))
+	}
+	return enforced
+}
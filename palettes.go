@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// runPalettesCommand runs 'csor palettes list' and 'csor palettes show <name>',
+// which browse the built-in palette library
+func runPalettesCommand(args []string) {
+	if len(args) == 0 {
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		listBuiltinPalettes()
+
+	case "show":
+		flags := flag.NewFlagSet("palettes show", flag.ExitOnError)
+		paletteOutput := flags.String("P", "", "Path to save the palette to, its extension selects the format")
+		flags.Parse(args[1:])
+		name := flags.Arg(0)
+		// flags may also follow the palette name
+		if flags.NArg() > 0 {
+			flags.Parse(flags.Args()[1:])
+		}
+		if name == "" || flags.NArg() > 0 {
+			printInvalidArgsMessage()
+			os.Exit(1)
+		}
+		validatePaletteOutput(*paletteOutput)
+		showBuiltinPalette(name, *paletteOutput)
+
+	default:
+		printInvalidArgsMessage()
+		os.Exit(1)
+	}
+}
+
+// listBuiltinPalettes prints the name, size and title of every built-in palette
+func listBuiltinPalettes() {
+	for _, name := range parsepalette.BuiltinNames() {
+		doc, err := parsepalette.BuiltinDocument(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%-22v %3d colors  %v by %v\n", name, len(doc.Entries), doc.Name, doc.Author)
+	}
+}
+
+// showBuiltinPalette prints a built-in palette as a text palette with the
+// color names as comments, or saves it when an output path is given
+func showBuiltinPalette(name, paletteOutputPath string) {
+	doc, err := parsepalette.BuiltinDocument(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	outputDocument(doc, paletteOutputPath, parsepalette.SaveOptions{})
+}
+
+// outputDocument saves the palette to the output path in the format selected
+// by its extension, or without a path prints it to stdout with printDocument
+func outputDocument(doc *parsepalette.Document, paletteOutputPath string, opts parsepalette.SaveOptions) {
+	if paletteOutputPath == "" {
+		printDocument(doc)
+		return
+	}
+
+	if err := parsepalette.SaveDocument(paletteOutputPath, doc, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// printDocument prints the palette as a text palette with the metadata as
+// comments, with a swatch of every color when stdout is a terminal
+func printDocument(doc *parsepalette.Document) {
+	if doc.Name != "" {
+		if doc.Author != "" {
+			fmt.Printf("// %v by %v\n", doc.Name, doc.Author)
+		} else {
+			fmt.Printf("// %v\n", doc.Name)
+		}
+	}
+	if doc.Source != "" {
+		fmt.Printf("// %v\n", doc.Source)
+	}
+
+	swatches := isTerminal(os.Stdout)
+	for _, e := range doc.Entries {
+		line := parsepalette.FormatHexColor(e.Color)
+		comment := e.Name
+		if e.Role != "" {
+			comment = strings.TrimSpace(fmt.Sprintf("%v (%v)", comment, e.Role))
+		}
+		if comment != "" {
+			line = fmt.Sprintf("%v // %v", line, comment)
+		}
+		if swatches {
+			line = fmt.Sprintf("%-40v \x1b[48;2;%d;%d;%dm      \x1b[0m", line, e.Color.R, e.Color.G, e.Color.B)
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+}
+
+// isTerminal reports whether the file is a terminal, where colors can be shown
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,87 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+const (
+	// temperatureShift is how far in OKLab a temperature of 1 moves a color
+	temperatureShift = 0.05
+	// warmHue is the OKLab hue in degrees colors move toward when warmed,
+	// cooling moves them to the opposite blue hue
+	warmHue = 60.0
+)
+
+// Adjustment is a change applied to every color of a palette, the zero value
+// leaves colors unchanged. The changes are applied in the order of the fields.
+type Adjustment struct {
+	// Invert replaces colors by their sRGB negative
+	Invert bool
+	// Lightness is added to the OKLCh lightness, which ranges from 0 to 1
+	Lightness float64
+	// Chroma is the relative change of OKLCh chroma, 0.2 is 20% more
+	// saturated and -1 removes all color
+	Chroma float64
+	// Hue is added to the OKLCh hue, in degrees
+	Hue float64
+	// Temperature shifts colors toward orange when positive and blue when
+	// negative, from -1 to 1
+	Temperature float64
+	// MixAmount moves colors toward MixColor, from 0 to 1, interpolated in
+	// MixSpace or OKLab if it is empty
+	MixColor  color.Color
+	MixAmount float64
+	MixSpace  colorspace.Space
+}
+
+// AdjustColor applies the adjustment to one color, mapping the result back
+// into the sRGB gamut.
+func AdjustColor(c color.Color, adj Adjustment) color.RGBA {
+	rgba := color.RGBAModel.Convert(c).(color.RGBA)
+	rgba.A = 255
+
+	if adj.Invert {
+		rgba = color.RGBA{R: 255 - rgba.R, G: 255 - rgba.G, B: 255 - rgba.B, A: 255}
+	}
+
+	if adj.Lightness != 0 || adj.Chroma != 0 || adj.Hue != 0 {
+		lch := colorspace.ToOKLCh(rgba)
+		lch.L = math.Max(0, math.Min(1, lch.L+adj.Lightness))
+		lch.C = math.Max(0, lch.C*(1+adj.Chroma))
+		lch.H = colorspace.NormalizeHue(lch.H + adj.Hue)
+		rgba = lch.ToRGBA()
+	}
+
+	if adj.Temperature != 0 {
+		lab := colorspace.ToOKLab(rgba)
+		angle := warmHue * math.Pi / 180
+		lab.A += adj.Temperature * temperatureShift * math.Cos(angle)
+		lab.B += adj.Temperature * temperatureShift * math.Sin(angle)
+		rgba = lab.LCh().ToRGBA()
+	}
+
+	if adj.MixColor != nil && adj.MixAmount != 0 {
+		space := adj.MixSpace
+		if space == "" {
+			space = colorspace.SpaceOKLab
+		}
+		rgba = colorspace.Mix(rgba, adj.MixColor, adj.MixAmount, space)
+	}
+
+	return rgba
+}
+
+// AdjustEntries returns a copy of the palette entries with the adjustment
+// applied to every color, keeping each color's metadata.
+func AdjustEntries(entries []parsepalette.Entry, adj Adjustment) []parsepalette.Entry {
+	adjusted := make([]parsepalette.Entry, len(entries))
+	for i, e := range entries {
+		adjusted[i] = e
+		adjusted[i].Color = AdjustColor(e.Color, adj)
+	}
+	return adjusted
+}
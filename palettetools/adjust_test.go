@@ -0,0 +1,69 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_AdjustColor(t *testing.T) {
+	pink := color.RGBA{0xea, 0x76, 0xcb, 0xff}
+	gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	pinkLCh := colorspace.ToOKLCh(pink)
+
+	if got := AdjustColor(pink, Adjustment{}); got != pink {
+		t.Errorf("Expected zero adjustment to keep %v, got %v", pink, got)
+	}
+	if got := AdjustColor(pink, Adjustment{Invert: true}); got != (color.RGBA{0x15, 0x89, 0x34, 0xff}) {
+		t.Errorf("Expected inverted color #158934, got %v", got)
+	}
+	if got := AdjustColor(gray, Adjustment{Chroma: 1, Hue: 90}); got != gray {
+		t.Errorf("Expected gray to stay gray, got %v", got)
+	}
+	if got := AdjustColor(pink, Adjustment{Chroma: -1}); !colorspace.ToOKLCh(got).IsAchromatic() {
+		t.Errorf("Expected fully desaturated color to be gray, got %v", got)
+	}
+	if got := AdjustColor(pink, Adjustment{MixColor: color.White, MixAmount: 1}); got != (color.RGBA{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("Expected full mix toward white to be white, got %v", got)
+	}
+
+	lighter := colorspace.ToOKLCh(AdjustColor(pink, Adjustment{Lightness: 0.1}))
+	if math.Abs(lighter.L-(pinkLCh.L+0.1)) > 0.01 {
+		t.Errorf("Expected lightness %.3f, got %.3f", pinkLCh.L+0.1, lighter.L)
+	}
+
+	shifted := colorspace.ToOKLCh(AdjustColor(pink, Adjustment{Hue: 120}))
+	expectedHue := colorspace.NormalizeHue(pinkLCh.H + 120)
+	if diff := math.Abs(shifted.H - expectedHue); math.Min(diff, 360-diff) > 2 {
+		t.Errorf("Expected hue %.1f, got %.1f", expectedHue, shifted.H)
+	}
+
+	warm := AdjustColor(gray, Adjustment{Temperature: 1})
+	cool := AdjustColor(gray, Adjustment{Temperature: -1})
+	if warm.R <= warm.B || cool.B <= cool.R {
+		t.Errorf("Expected warmed gray to be red leaning and cooled gray blue leaning, got %v and %v", warm, cool)
+	}
+}
+
+func Test_AdjustEntries(t *testing.T) {
+	entries := []parsepalette.Entry{
+		{Color: color.RGBA{0x00, 0x00, 0x00, 0xff}, Name: "Base", Role: "background", Weight: 0.5},
+		{Color: color.RGBA{0xff, 0xff, 0xff, 0xff}, Name: "Text", Role: "foreground", Weight: 0.5},
+	}
+
+	adjusted := AdjustEntries(entries, Adjustment{Invert: true})
+	if adjusted[0].Color != entries[1].Color || adjusted[1].Color != entries[0].Color {
+		t.Errorf("Expected black and white to swap, got %v", adjusted)
+	}
+	for i := range entries {
+		if adjusted[i].Name != entries[i].Name || adjusted[i].Role != entries[i].Role || adjusted[i].Weight != entries[i].Weight {
+			t.Errorf("Expected metadata of %v to be kept, got %v", entries[i], adjusted[i])
+		}
+	}
+	if entries[0].Color != (color.RGBA{0x00, 0x00, 0x00, 0xff}) {
+		t.Errorf("Expected input entries to be unchanged, got %v", entries)
+	}
+}
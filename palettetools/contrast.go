@@ -0,0 +1,117 @@
+package palettetools
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// ContrastMethod is the way the contrast between two colors is measured.
+type ContrastMethod string
+
+const (
+	// ContrastWCAG is the WCAG 2.x contrast ratio, from 1 to 21
+	ContrastWCAG ContrastMethod = "wcag"
+	// ContrastAPCA is the absolute APCA lightness contrast Lc, from 0 to about 108
+	ContrastAPCA ContrastMethod = "apca"
+)
+
+const (
+	// foregroundRole is the role of a palette's main text color
+	foregroundRole = "foreground"
+	// onRolePrefix marks Material style roles such as 'on-primary' and
+	// 'inverse-on-surface', the text colors shown on 'primary' and
+	// 'inverse-surface'
+	onRolePrefix = "on-"
+	// accentRole is the role of a color shown on the background, numbered
+	// like 'accent-2' when a palette has several
+	accentRole = "accent"
+)
+
+// ParseContrastMethod validates a contrast method name given on the command line.
+func ParseContrastMethod(name string) (ContrastMethod, error) {
+	switch method := ContrastMethod(name); method {
+	case ContrastWCAG, ContrastAPCA:
+		return method, nil
+	default:
+		return "", fmt.Errorf("invalid contrast method '%v' (expected wcag or apca)", name)
+	}
+}
+
+// DefaultMinContrast is the contrast body text needs under a method: the WCAG
+// AA ratio of 4.5, or Lc 60 in APCA.
+func (m ContrastMethod) DefaultMinContrast() float64 {
+	if m == ContrastAPCA {
+		return 60
+	}
+	return 4.5
+}
+
+// Contrast measures the contrast of a foreground color on a background.
+// APCA contrast is given as an absolute value so that both methods grow with
+// legibility.
+func (m ContrastMethod) Contrast(foreground, background color.Color) float64 {
+	if m == ContrastAPCA {
+		return math.Abs(colorspace.APCAContrast(foreground, background))
+	}
+	return colorspace.ContrastRatio(foreground, background)
+}
+
+// ContrastPair is a foreground and a background entry, by index, whose colors
+// are shown on top of each other.
+type ContrastPair struct {
+	Foreground, Background int
+}
+
+// ContrastPairs finds the pairs of entries that must be legible on each other
+// by their roles: 'foreground' and accents like 'accent-2' on 'background',
+// and roles like 'on-primary' on the role they name ('primary').
+func ContrastPairs(entries []parsepalette.Entry) []ContrastPair {
+	roles := make(map[string]int)
+	for i, e := range entries {
+		if e.Role != "" {
+			roles[e.Role] = i
+		}
+	}
+
+	var pairs []ContrastPair
+	for i, e := range entries {
+		var target string
+		switch {
+		case e.Role == foregroundRole, isAccentRole(e.Role):
+			target = backgroundRole
+		case strings.HasPrefix(e.Role, onRolePrefix):
+			target = strings.TrimPrefix(e.Role, onRolePrefix)
+		case strings.Contains(e.Role, "-"+onRolePrefix):
+			target = strings.Replace(e.Role, "-"+onRolePrefix, "-", 1)
+		default:
+			continue
+		}
+		if background, ok := roles[target]; ok {
+			pairs = append(pairs, ContrastPair{Foreground: i, Background: background})
+		}
+	}
+	return pairs
+}
+
+// isAccentRole reports whether a role is 'accent' or a numbered accent like
+// 'accent-2', but not a role that only starts like one such as 'accentuate'
+func isAccentRole(role string) bool {
+	if role == accentRole {
+		return true
+	}
+	number, found := strings.CutPrefix(role, accentRole+"-")
+	if !found || number == "" {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
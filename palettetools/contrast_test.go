@@ -0,0 +1,62 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_ParseContrastMethod(t *testing.T) {
+	for _, name := range []string{"wcag", "apca"} {
+		if method, err := ParseContrastMethod(name); err != nil || string(method) != name {
+			t.Errorf("Expected contrast method '%v', got '%v' (%v)", name, method, err)
+		}
+	}
+	if _, err := ParseContrastMethod("luminance"); err == nil {
+		t.Errorf("Expected an error for an unknown contrast method")
+	}
+}
+
+func Test_ContrastMethod(t *testing.T) {
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+
+	if got := ContrastWCAG.Contrast(black, white); math.Abs(got-21) > 1e-9 {
+		t.Errorf("Expected WCAG contrast 21, got %v", got)
+	}
+	onWhite, onBlack := ContrastAPCA.Contrast(black, white), ContrastAPCA.Contrast(white, black)
+	if onWhite < 100 || onBlack < 100 {
+		t.Errorf("Expected positive APCA contrast above 100 both ways, got %.1f and %.1f", onWhite, onBlack)
+	}
+}
+
+func Test_ContrastPairs(t *testing.T) {
+	entries := []parsepalette.Entry{
+		{Role: "background"},
+		{Role: "foreground"},
+		{Role: "primary"},
+		{Role: "on-primary"},
+		{Role: "on-secondary"},
+		{Role: "inverse-surface"},
+		{Role: "inverse-on-surface"},
+		{Role: "accent-2"},
+		{},
+		{Role: "accent"},
+		{Role: "accentuate"},
+		{Role: "accent-bright"},
+	}
+	expected := []ContrastPair{
+		{Foreground: 1, Background: 0},
+		{Foreground: 3, Background: 2},
+		{Foreground: 6, Background: 5},
+		{Foreground: 7, Background: 0},
+		{Foreground: 9, Background: 0},
+	}
+
+	if got := ContrastPairs(entries); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected contrast pairs %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,108 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+const (
+	// distinguishableDeltaE is the OKLab distance two colors should keep with a
+	// color vision deficiency to be told apart at a glance
+	distinguishableDeltaE = 0.1
+	// confusionWeight is how much more keeping colors apart with a deficiency
+	// counts than keeping every color close to the one it replaces
+	confusionWeight = 4
+	// minRemapCoverage is the share of an image below which a color is left
+	// alone, such as the blended colors along anti-aliased edges
+	minRemapCoverage = 0.002
+	// maxRemapSweeps bounds the rounds of improving the assignment
+	maxRemapSweeps = 16
+)
+
+// DistinguishableRemap chooses a palette color for each of the used palette
+// colors, keeping colors that can be told apart with normal vision apart with
+// a color vision deficiency. usage holds the palette colors an image was
+// mapped to with the share of the image each covers. Colors are moved to other
+// palette colors only when that makes them easier to tell apart, and as
+// little as possible, which turns the replacement of every pixel's closest
+// palette color into an assignment that stays legible with the deficiency.
+// Colors missing from the returned map are kept.
+func DistinguishableRemap(usage []parsepalette.Entry, palette color.Palette,
+	sim colorspace.CVDSimulation) map[color.RGBA]color.RGBA {
+	var candidates []color.RGBA
+	seen := make(map[color.RGBA]struct{})
+	for _, c := range palette {
+		rgba := color.RGBAModel.Convert(c).(color.RGBA)
+		if _, exists := seen[rgba]; !exists {
+			seen[rgba] = struct{}{}
+			candidates = append(candidates, rgba)
+		}
+	}
+	labs := make([]colorspace.OKLab, len(candidates))
+	simulated := make([]colorspace.OKLab, len(candidates))
+	for i, c := range candidates {
+		labs[i] = colorspace.ToOKLab(c)
+		simulated[i] = colorspace.ToOKLab(sim.Simulate(c))
+	}
+
+	var regions []colorspace.OKLab
+	var regionColors []color.RGBA
+	var assignment []int
+	for _, e := range usage {
+		if e.Weight < minRemapCoverage {
+			continue
+		}
+		for i, c := range candidates {
+			if c == e.Color {
+				regions = append(regions, labs[i])
+				regionColors = append(regionColors, c)
+				assignment = append(assignment, i)
+			}
+		}
+	}
+
+	// cost is what assigning a candidate to a region adds to the total: its
+	// distance from the region's color, and how much less apart than before
+	// it is from every other region's assigned color with the deficiency
+	cost := func(region, candidate int) float64 {
+		total := colorspace.DeltaEOK(regions[region], labs[candidate])
+		for other := range regions {
+			if other == region {
+				continue
+			}
+			want := math.Min(colorspace.DeltaEOK(regions[region], regions[other]), distinguishableDeltaE)
+			got := colorspace.DeltaEOK(simulated[candidate], simulated[assignment[other]])
+			if got < want {
+				total += confusionWeight * (want - got)
+			}
+		}
+		return total
+	}
+
+	for sweep := 0; sweep < maxRemapSweeps; sweep++ {
+		changed := false
+		for region := range regions {
+			best, bestCost := assignment[region], cost(region, assignment[region])
+			for candidate := range candidates {
+				if c := cost(region, candidate); c < bestCost-1e-9 {
+					best, bestCost = candidate, c
+				}
+			}
+			if best != assignment[region] {
+				assignment[region], changed = best, true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	remap := make(map[color.RGBA]color.RGBA, len(regions))
+	for region, candidate := range assignment {
+		remap[regionColors[region]] = candidates[candidate]
+	}
+	return remap
+}
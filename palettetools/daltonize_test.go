@@ -0,0 +1,51 @@
+package palettetools
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_DistinguishableRemap(t *testing.T) {
+	red := color.RGBA{0xd0, 0x30, 0x30, 0xff}
+	green := color.RGBA{0x40, 0x90, 0x30, 0xff}
+	blue := color.RGBA{0x30, 0x50, 0xd0, 0xff}
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	edge := color.RGBA{0x88, 0x60, 0x30, 0xff}
+	palette := color.Palette{red, green, blue, white, edge}
+	sim := colorspace.CVDSimulation{Deficiency: colorspace.Deuteranopia, Method: colorspace.CVDMachado, Severity: 1}
+
+	usage := []parsepalette.Entry{
+		{Color: white, Weight: 0.5},
+		{Color: red, Weight: 0.25},
+		{Color: green, Weight: 0.2499},
+		{Color: edge, Weight: 0.0001},
+	}
+	remap := DistinguishableRemap(usage, palette, sim)
+
+	if remap[white] != white {
+		t.Errorf("Expected white to be kept, got %v", remap[white])
+	}
+	if _, exists := remap[edge]; exists {
+		t.Errorf("Expected rare color %v to be left alone, got %v", edge, remap[edge])
+	}
+	simulatedDistance := func(a, b color.RGBA) float64 {
+		return colorspace.DeltaEOK(colorspace.ToOKLab(sim.Simulate(a)), colorspace.ToOKLab(sim.Simulate(b)))
+	}
+	before, after := simulatedDistance(red, green), simulatedDistance(remap[red], remap[green])
+	if after <= before {
+		t.Errorf("Expected red and green to be remapped further apart than %.3f, got %v and %v at %.3f",
+			before, remap[red], remap[green], after)
+	}
+	if remap[red] != red && remap[green] != green {
+		t.Errorf("Expected one of red and green to be kept, got %v and %v", remap[red], remap[green])
+	}
+
+	sim.Deficiency = colorspace.Tritanopia
+	remap = DistinguishableRemap(usage, palette, sim)
+	if remap[red] != red || remap[green] != green {
+		t.Errorf("Expected red and green to be kept with tritanopia, got %v and %v", remap[red], remap[green])
+	}
+}
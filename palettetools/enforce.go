@@ -0,0 +1,104 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// contrastSearchSteps bounds the binary search for a legible lightness
+const contrastSearchSteps = 24
+
+// EnforceContrast changes the foreground of every pair found by ContrastPairs
+// that has less than minContrast, moving its OKLCh lightness as little as
+// possible, away from its background, until the pair reaches minContrast.
+// Hue and chroma are kept as far as the sRGB gamut allows, backgrounds and
+// other entries are left as they are. Pairs that cannot reach minContrast
+// even in black or white get whichever of them has more contrast and are
+// returned as unmet.
+func EnforceContrast(entries []parsepalette.Entry, method ContrastMethod,
+	minContrast float64) (enforced []parsepalette.Entry, unmet []ContrastPair) {
+	enforced = make([]parsepalette.Entry, len(entries))
+	copy(enforced, entries)
+
+	pairs := ContrastPairs(enforced)
+	for _, pair := range pairs {
+		foreground := &enforced[pair.Foreground]
+		foreground.Color = EnsureContrast(foreground.Color, enforced[pair.Background].Color, method, minContrast)
+	}
+
+	// a foreground shown on several backgrounds may have lost contrast again
+	for _, pair := range pairs {
+		if method.Contrast(enforced[pair.Foreground].Color, enforced[pair.Background].Color) < minContrast {
+			unmet = append(unmet, pair)
+		}
+	}
+	return enforced, unmet
+}
+
+// EnsureContrast returns the color closest to foreground in OKLCh lightness,
+// with its hue and chroma, that has at least minContrast on background. When
+// no lightness gets there it returns black or white, whichever has more
+// contrast.
+func EnsureContrast(foreground, background color.RGBA, method ContrastMethod, minContrast float64) color.RGBA {
+	if method.Contrast(foreground, background) >= minContrast {
+		return foreground
+	}
+
+	lch := colorspace.ToOKLCh(foreground)
+	withLightness := func(l float64) color.RGBA {
+		return colorspace.OKLCh{L: l, C: lch.C, H: lch.H}.ToRGBA()
+	}
+	backgroundLuminance := colorspace.RelativeLuminance(background)
+	// legible reports whether a color has enough contrast while being on the
+	// darker or lighter side of the background, where contrast grows with
+	// the distance in lightness
+	legible := func(c color.RGBA, darker bool) bool {
+		if luminance := colorspace.RelativeLuminance(c); darker != (luminance < backgroundLuminance) {
+			return false
+		}
+		return method.Contrast(c, background) >= minContrast
+	}
+
+	var candidates []float64
+	if legible(withLightness(0), true) {
+		low, high := 0.0, lch.L
+		for i := 0; i < contrastSearchSteps; i++ {
+			if mid := (low + high) / 2; legible(withLightness(mid), true) {
+				low = mid
+			} else {
+				high = mid
+			}
+		}
+		candidates = append(candidates, low)
+	}
+	if legible(withLightness(1), false) {
+		low, high := lch.L, 1.0
+		for i := 0; i < contrastSearchSteps; i++ {
+			if mid := (low + high) / 2; legible(withLightness(mid), false) {
+				high = mid
+			} else {
+				low = mid
+			}
+		}
+		candidates = append(candidates, high)
+	}
+
+	if len(candidates) == 0 {
+		black, white := withLightness(0), withLightness(1)
+		if method.Contrast(black, background) > method.Contrast(white, background) {
+			return black
+		}
+		return white
+	}
+
+	closest := candidates[0]
+	for _, l := range candidates[1:] {
+		if math.Abs(l-lch.L) < math.Abs(closest-lch.L) {
+			closest = l
+		}
+	}
+	return withLightness(closest)
+}
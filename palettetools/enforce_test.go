@@ -0,0 +1,85 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_EnsureContrast(t *testing.T) {
+	dark := color.RGBA{0x1e, 0x1e, 0x2e, 0xff}
+	light := color.RGBA{0xfd, 0xf6, 0xe3, 0xff}
+	gray := color.RGBA{0x76, 0x76, 0x76, 0xff}
+
+	tests := []struct {
+		foreground, background color.RGBA
+		method                 ContrastMethod
+		minContrast            float64
+	}{
+		{color.RGBA{0x58, 0x5b, 0x70, 0xff}, dark, ContrastWCAG, 4.5},
+		{color.RGBA{0x65, 0x7b, 0x83, 0xff}, light, ContrastWCAG, 7},
+		{color.RGBA{0xea, 0x76, 0xcb, 0xff}, light, ContrastAPCA, 75},
+		{color.RGBA{0x80, 0x80, 0x80, 0xff}, gray, ContrastWCAG, 4.5},
+	}
+
+	for _, tt := range tests {
+		got := EnsureContrast(tt.foreground, tt.background, tt.method, tt.minContrast)
+		if contrast := tt.method.Contrast(got, tt.background); contrast < tt.minContrast {
+			t.Errorf("Expected %v contrast of at least %v for %v on %v, got %v with %.2f",
+				tt.method, tt.minContrast, tt.foreground, tt.background, got, contrast)
+		}
+
+		// one step of lightness closer to the original must not be enough
+		before, after := colorspace.ToOKLCh(tt.foreground), colorspace.ToOKLCh(got)
+		closer := after
+		closer.L += math.Copysign(0.01, before.L-after.L)
+		if tt.method.Contrast(closer.ToRGBA(), tt.background) >= tt.minContrast &&
+			math.Abs(closer.L-before.L) < math.Abs(after.L-before.L) {
+			t.Errorf("Expected %v to be the least change of %v, but %v has enough contrast",
+				got, tt.foreground, closer.ToRGBA())
+		}
+	}
+
+	legible := color.RGBA{0xcd, 0xd6, 0xf4, 0xff}
+	if got := EnsureContrast(legible, dark, ContrastWCAG, 4.5); got != legible {
+		t.Errorf("Expected legible color %v to be kept, got %v", legible, got)
+	}
+	if got := EnsureContrast(gray, gray, ContrastWCAG, 21); got != (color.RGBA{0x00, 0x00, 0x00, 0xff}) {
+		t.Errorf("Expected black as the best effort on %v, got %v", gray, got)
+	}
+}
+
+func Test_EnforceContrast(t *testing.T) {
+	entries := []parsepalette.Entry{
+		{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, Role: "background"},
+		{Color: color.RGBA{0x45, 0x47, 0x5a, 0xff}, Role: "foreground"},
+		{Color: color.RGBA{0x58, 0x5b, 0x70, 0xff}, Role: "accent"},
+		{Color: color.RGBA{0x31, 0x32, 0x44, 0xff}},
+		{Color: color.RGBA{0x80, 0x80, 0x80, 0xff}, Role: "surface"},
+		{Color: color.RGBA{0x80, 0x80, 0x80, 0xff}, Role: "on-surface"},
+	}
+
+	enforced, unmet := EnforceContrast(entries, ContrastWCAG, 7)
+	for _, i := range []int{1, 2} {
+		if contrast := ContrastWCAG.Contrast(enforced[i].Color, enforced[0].Color); contrast < 7 {
+			t.Errorf("Expected %v to reach contrast 7, got %.2f", enforced[i].Role, contrast)
+		}
+		if enforced[i].Role != entries[i].Role {
+			t.Errorf("Expected role '%v' to be kept, got '%v'", entries[i].Role, enforced[i].Role)
+		}
+	}
+	for _, i := range []int{0, 3, 4} {
+		if enforced[i] != entries[i] {
+			t.Errorf("Expected entry %v to be unchanged, got %v", entries[i], enforced[i])
+		}
+	}
+	if len(unmet) != 1 || unmet[0] != (ContrastPair{Foreground: 5, Background: 4}) {
+		t.Errorf("Expected the on-surface pair to be unmet, got %v", unmet)
+	}
+	if entries[1].Color != (color.RGBA{0x45, 0x47, 0x5a, 0xff}) {
+		t.Errorf("Expected the input entries to be left alone")
+	}
+}
@@ -0,0 +1,105 @@
+package palettetools
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+)
+
+// HarmonyScheme selects the hues of a generated color harmony.
+type HarmonyScheme string
+
+const (
+	HarmonyComplementary HarmonyScheme = "complementary"
+	HarmonyAnalogous     HarmonyScheme = "analogous"
+	HarmonyTriadic       HarmonyScheme = "triadic"
+	HarmonySplit         HarmonyScheme = "split"
+	HarmonyTetradic      HarmonyScheme = "tetradic"
+)
+
+// harmony tones stay within this OKLCh lightness range, spaced at most
+// maxToneStep apart
+const (
+	minToneLightness = 0.2
+	maxToneLightness = 0.97
+	maxToneStep      = 0.1
+)
+
+// harmonyHues are the hue offsets in degrees from the seed of each scheme
+var harmonyHues = map[HarmonyScheme][]float64{
+	HarmonyComplementary: {0, 180},
+	HarmonyAnalogous:     {0, 30, -30},
+	HarmonyTriadic:       {0, 120, 240},
+	HarmonySplit:         {0, 150, 210},
+	HarmonyTetradic:      {0, 90, 180, 270},
+}
+
+// ParseHarmonyScheme validates a harmony scheme name given on the command line.
+func ParseHarmonyScheme(name string) (HarmonyScheme, error) {
+	scheme := HarmonyScheme(name)
+	if _, ok := harmonyHues[scheme]; !ok {
+		return "", fmt.Errorf("invalid harmony scheme '%v' "+
+			"(expected complementary, analogous, triadic, split or tetradic)", name)
+	}
+	return scheme, nil
+}
+
+// Harmony generates a palette of n colors around the seed in OKLCh. The seed's
+// hue is rotated to the hues of the scheme, keeping its chroma, and each hue
+// gets an equal share of the colors as lighter and darker tones. The seed is
+// the first color, followed by its own tones and then those of the other hues.
+func Harmony(seed color.Color, scheme HarmonyScheme, n int) (color.Palette, error) {
+	offsets, ok := harmonyHues[scheme]
+	if !ok {
+		return nil, fmt.Errorf("invalid harmony scheme '%v'", scheme)
+	}
+	seedLCh := colorspace.ToOKLCh(seed)
+	if seedLCh.IsAchromatic() {
+		return nil, fmt.Errorf("seed color is gray, a harmony needs a seed with a hue")
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of colors %v", n)
+	}
+
+	hues := min(len(offsets), n)
+	palette := make(color.Palette, 0, n)
+	seenColors := make(map[color.RGBA]struct{})
+	for h := 0; h < hues; h++ {
+		tones := n / hues
+		if h < n%hues {
+			tones++
+		}
+		for i, lightness := range toneLightness(seedLCh.L, tones) {
+			c := colorspace.OKLCh{L: lightness, C: seedLCh.C, H: seedLCh.H + offsets[h]}.ToRGBA()
+			if h == 0 && i == 0 {
+				c = color.RGBAModel.Convert(seed).(color.RGBA)
+			}
+			if _, exists := seenColors[c]; exists {
+				continue
+			}
+			seenColors[c] = struct{}{}
+			palette = append(palette, c)
+		}
+	}
+
+	return palette, nil
+}
+
+// toneLightness returns count lightness values, the first being base and the
+// rest stepping alternately lighter and darker from it within the tone range
+func toneLightness(base float64, count int) []float64 {
+	step := min(maxToneStep, (maxToneLightness-minToneLightness)/float64(count))
+	lightness := []float64{base}
+	for i := 1; len(lightness) < count; i++ {
+		for _, l := range []float64{base + float64(i)*step, base - float64(i)*step} {
+			if len(lightness) < count && l >= minToneLightness && l <= maxToneLightness {
+				lightness = append(lightness, l)
+			}
+		}
+		if base+float64(i)*step > maxToneLightness && base-float64(i)*step < minToneLightness {
+			break
+		}
+	}
+	return lightness
+}
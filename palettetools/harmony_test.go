@@ -0,0 +1,64 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+)
+
+func Test_ParseHarmonyScheme(t *testing.T) {
+	for _, name := range []string{"complementary", "analogous", "triadic", "split", "tetradic"} {
+		if _, err := ParseHarmonyScheme(name); err != nil {
+			t.Errorf("Expected no error for %q, got: %v", name, err)
+		}
+	}
+	if _, err := ParseHarmonyScheme("square"); err == nil {
+		t.Errorf("Expected error for invalid scheme, got none")
+	}
+}
+
+func Test_Harmony(t *testing.T) {
+	seed := color.RGBA{0xea, 0x76, 0xcb, 0xff}
+	seedHue := colorspace.ToOKLCh(seed).H
+
+	tests := []struct {
+		scheme HarmonyScheme
+		n      int
+		hues   []float64
+	}{
+		{HarmonyComplementary, 2, []float64{0, 180}},
+		{HarmonyTriadic, 12, []float64{0, 120, 240}},
+		{HarmonySplit, 6, []float64{0, 150, 210}},
+		{HarmonyTetradic, 8, []float64{0, 90, 180, 270}},
+		{HarmonyAnalogous, 9, []float64{0, 30, -30}},
+	}
+
+	for _, tt := range tests {
+		palette, err := Harmony(seed, tt.scheme, tt.n)
+		if err != nil {
+			t.Fatalf("Expected no error for %v, got: %v", tt.scheme, err)
+		}
+		if len(palette) != tt.n {
+			t.Fatalf("Expected %v colors for %v, got %v", tt.n, tt.scheme, len(palette))
+		}
+		if palette[0] != seed {
+			t.Errorf("Expected %v to start with the seed, got %v", tt.scheme, palette[0])
+		}
+
+		perHue := tt.n / len(tt.hues)
+		for i, c := range palette {
+			lch := colorspace.ToOKLCh(c)
+			expectedHue := colorspace.NormalizeHue(seedHue + tt.hues[i/perHue])
+			diff := math.Abs(lch.H - expectedHue)
+			if math.Min(diff, 360-diff) > 5 {
+				t.Errorf("Expected %v color %v to have hue %.0f, got %.0f", tt.scheme, c, expectedHue, lch.H)
+			}
+		}
+	}
+
+	if _, err := Harmony(color.RGBA{0x80, 0x80, 0x80, 0xff}, HarmonyTriadic, 6); err == nil {
+		t.Errorf("Expected error for a gray seed, got none")
+	}
+}
@@ -0,0 +1,177 @@
+package palettetools
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// LintKind is the kind of problem a palette lint reports.
+type LintKind string
+
+const (
+	LintNearDuplicate LintKind = "near-duplicate"
+	LintExtreme       LintKind = "extreme"
+	LintSpread        LintKind = "spread"
+	LintContrast      LintKind = "contrast"
+)
+
+// LintOptions are the thresholds of a palette lint.
+type LintOptions struct {
+	// MinDeltaE is the OKLab distance below which two colors are near-duplicates
+	MinDeltaE float64
+	// MinExtremeContrast is the WCAG contrast ratio against black or white
+	// below which a color is too close to them, black and white themselves
+	// are fine
+	MinExtremeContrast float64
+	// MinLightnessSpread is the least difference of OKLab lightness between
+	// the darkest and the lightest color
+	MinLightnessSpread float64
+	// ContrastMethod measures foreground and background pairs, which need at
+	// least MinContrast
+	ContrastMethod ContrastMethod
+	MinContrast    float64
+}
+
+// DefaultLintOptions returns the thresholds used when none are given.
+func DefaultLintOptions() LintOptions {
+	return LintOptions{
+		MinDeltaE:          0.02,
+		MinExtremeContrast: 1.05,
+		MinLightnessSpread: 0.3,
+		ContrastMethod:     ContrastWCAG,
+		MinContrast:        ContrastWCAG.DefaultMinContrast(),
+	}
+}
+
+// LintIssue is a problem found in a palette, Entries are the indices of the
+// entries involved.
+type LintIssue struct {
+	Kind    LintKind
+	Entries []int
+	Message string
+}
+
+func (issue LintIssue) String() string {
+	return fmt.Sprintf("%v: %v", issue.Kind, issue.Message)
+}
+
+// Lint checks a palette for colors that are hard to tell apart or to read:
+// near-duplicate and duplicate pairs, colors that are almost black or white, too little
+// lightness spread, and foreground and background pairs (see ContrastPairs)
+// with too little contrast. Colors with a role are used where their role says,
+// so two of them may share a color and a background may be almost white:
+// pairs of role colors are not compared, and role colors are not checked for
+// being almost black or white.
+func Lint(entries []parsepalette.Entry, opts LintOptions) []LintIssue {
+	var issues []LintIssue
+
+	labs := make([]colorspace.OKLab, len(entries))
+	for i, e := range entries {
+		labs[i] = colorspace.ToOKLab(e.Color)
+	}
+
+	for i := range entries {
+		for j := i + 1; j < len(entries); j++ {
+			if entries[i].Role != "" && entries[j].Role != "" {
+				continue
+			}
+			if entries[i].Color == entries[j].Color && entries[j].Role == "" && entries[j].Name == "" {
+				issues = append(issues, LintIssue{
+					Kind:    LintNearDuplicate,
+					Entries: []int{i, j},
+					Message: fmt.Sprintf("%v and %v are the same color, the second is left out when the palette is read",
+						describeEntry(entries[i]), describeEntry(entries[j])),
+				})
+			} else if deltaE := colorspace.DeltaEOK(labs[i], labs[j]); deltaE < opts.MinDeltaE {
+				issues = append(issues, LintIssue{
+					Kind:    LintNearDuplicate,
+					Entries: []int{i, j},
+					Message: fmt.Sprintf("%v and %v are nearly the same color (ΔE %.3f)",
+						describeEntry(entries[i]), describeEntry(entries[j]), deltaE),
+				})
+			}
+		}
+	}
+
+	black := color.RGBA{A: 255}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	for i, e := range entries {
+		if e.Color == black || e.Color == white || e.Role != "" {
+			continue
+		}
+		for _, extreme := range []struct {
+			name  string
+			color color.RGBA
+		}{{"black", black}, {"white", white}} {
+			if contrast := colorspace.ContrastRatio(e.Color, extreme.color); contrast < opts.MinExtremeContrast {
+				issues = append(issues, LintIssue{
+					Kind:    LintExtreme,
+					Entries: []int{i},
+					Message: fmt.Sprintf("%v is too close to %v (contrast %.2f:1)",
+						describeEntry(e), extreme.name, contrast),
+				})
+			}
+		}
+	}
+
+	if len(entries) > 1 {
+		darkest, lightest := 0, 0
+		for i := range labs {
+			if labs[i].L < labs[darkest].L {
+				darkest = i
+			}
+			if labs[i].L > labs[lightest].L {
+				lightest = i
+			}
+		}
+		if spread := labs[lightest].L - labs[darkest].L; spread < opts.MinLightnessSpread {
+			issues = append(issues, LintIssue{
+				Kind:    LintSpread,
+				Entries: []int{darkest, lightest},
+				Message: fmt.Sprintf("lightness only spreads %.2f from %v to %v (want %.2f)",
+					spread, describeEntry(entries[darkest]), describeEntry(entries[lightest]), opts.MinLightnessSpread),
+			})
+		}
+	}
+
+	for _, pair := range ContrastPairs(entries) {
+		foreground, background := entries[pair.Foreground], entries[pair.Background]
+		if contrast := opts.ContrastMethod.Contrast(foreground.Color, background.Color); contrast < opts.MinContrast {
+			issues = append(issues, LintIssue{
+				Kind:    LintContrast,
+				Entries: []int{pair.Foreground, pair.Background},
+				Message: fmt.Sprintf("%v on %v has %v contrast %v (want %v)",
+					describeEntry(foreground), describeEntry(background), opts.ContrastMethod,
+					formatContrast(opts.ContrastMethod, contrast), formatContrast(opts.ContrastMethod, opts.MinContrast)),
+			})
+		}
+	}
+
+	return issues
+}
+
+// formatContrast formats a contrast as a WCAG ratio or an APCA Lc value
+func formatContrast(method ContrastMethod, contrast float64) string {
+	if method == ContrastAPCA {
+		return fmt.Sprintf("Lc %.1f", contrast)
+	}
+	return fmt.Sprintf("%.2f:1", contrast)
+}
+
+// describeEntry names an entry in messages by its hex code, name and role
+func describeEntry(e parsepalette.Entry) string {
+	hex := parsepalette.FormatHexColor(e.Color)
+	switch {
+	case e.Name != "" && e.Role != "":
+		return fmt.Sprintf("%v (%v, %v)", hex, e.Name, e.Role)
+	case e.Name != "":
+		return fmt.Sprintf("%v (%v)", hex, e.Name)
+	case e.Role != "":
+		return fmt.Sprintf("%v (%v)", hex, e.Role)
+	default:
+		return hex
+	}
+}
@@ -0,0 +1,105 @@
+package palettetools
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_Lint(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []parsepalette.Entry
+		expected []LintKind
+	}{
+		{
+			name: "clean palette",
+			entries: []parsepalette.Entry{
+				{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, Role: "background"},
+				{Color: color.RGBA{0xcd, 0xd6, 0xf4, 0xff}, Role: "foreground"},
+				{Color: color.RGBA{0x00, 0x00, 0x00, 0xff}},
+			},
+		},
+		{
+			name: "near-duplicates",
+			entries: []parsepalette.Entry{
+				{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}},
+				{Color: color.RGBA{0x1f, 0x1e, 0x2e, 0xff}},
+				{Color: color.RGBA{0xcd, 0xd6, 0xf4, 0xff}},
+			},
+			expected: []LintKind{LintNearDuplicate},
+		},
+		{
+			name: "duplicates",
+			entries: []parsepalette.Entry{
+				{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}},
+				{Color: color.RGBA{0xcd, 0xd6, 0xf4, 0xff}},
+				{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}},
+			},
+			expected: []LintKind{LintNearDuplicate},
+		},
+		{
+			name: "roles sharing colors",
+			entries: []parsepalette.Entry{
+				{Color: color.RGBA{0xff, 0xfb, 0xfa, 0xff}, Role: "background"},
+				{Color: color.RGBA{0xff, 0xfb, 0xfa, 0xff}, Role: "surface"},
+				{Color: color.RGBA{0x1c, 0x1b, 0x1e, 0xff}, Role: "foreground"},
+				{Color: color.RGBA{0x1c, 0x1b, 0x1e, 0xff}, Role: "on-surface"},
+			},
+		},
+		{
+			name: "close to black and white",
+			entries: []parsepalette.Entry{
+				{Color: color.RGBA{0x05, 0x05, 0x05, 0xff}},
+				{Color: color.RGBA{0xfe, 0xfe, 0xfe, 0xff}},
+			},
+			expected: []LintKind{LintExtreme, LintExtreme},
+		},
+		{
+			name: "lightness spread",
+			entries: []parsepalette.Entry{
+				{Color: color.RGBA{0xea, 0x76, 0xcb, 0xff}},
+				{Color: color.RGBA{0x1e, 0x99, 0xf5, 0xff}},
+			},
+			expected: []LintKind{LintSpread},
+		},
+		{
+			name: "low contrast",
+			entries: []parsepalette.Entry{
+				{Color: color.RGBA{0xfd, 0xf6, 0xe3, 0xff}, Role: "background"},
+				{Color: color.RGBA{0x65, 0x7b, 0x83, 0xff}, Role: "foreground"},
+			},
+			expected: []LintKind{LintContrast},
+		},
+	}
+
+	for _, tt := range tests {
+		var got []LintKind
+		for _, issue := range Lint(tt.entries, DefaultLintOptions()) {
+			got = append(got, issue.Kind)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Expected %v issues %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func Test_LintContrastMethod(t *testing.T) {
+	entries := []parsepalette.Entry{
+		{Color: color.RGBA{0x00, 0x00, 0x00, 0xff}, Role: "background"},
+		{Color: color.RGBA{0x88, 0x88, 0x88, 0xff}, Role: "foreground"},
+	}
+
+	opts := DefaultLintOptions()
+	if issues := Lint(entries, opts); len(issues) != 0 {
+		t.Errorf("Expected WCAG contrast to pass, got %v", issues)
+	}
+
+	opts.ContrastMethod, opts.MinContrast = ContrastAPCA, ContrastAPCA.DefaultMinContrast()
+	issues := Lint(entries, opts)
+	if len(issues) != 1 || issues[0].Kind != LintContrast || !reflect.DeepEqual(issues[0].Entries, []int{1, 0}) {
+		t.Errorf("Expected an APCA contrast issue of entries [1 0], got %v", issues)
+	}
+}
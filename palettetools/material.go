@@ -0,0 +1,182 @@
+package palettetools
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// TonalPalette is a hue and chroma in HCT, its colors differ only in tone.
+type TonalPalette struct {
+	Hue, Chroma float64
+}
+
+// Tone returns the color of the palette at a tone from 0 (black) to 100 (white).
+func (p TonalPalette) Tone(tone float64) color.RGBA {
+	return colorspace.HCT{Hue: p.Hue, Chroma: p.Chroma, Tone: tone}.ToRGBA()
+}
+
+// MaterialTones are the tones Material 3 takes from a tonal palette.
+var MaterialTones = []float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99, 100}
+
+const (
+	// minPrimaryChroma keeps the primary palette colorful for dull seeds
+	minPrimaryChroma = 48
+	// minSeedChroma is the HCT chroma below which an image color is too gray
+	// to seed a scheme
+	minSeedChroma = 5
+)
+
+// CorePalette holds the tonal palettes a Material 3 scheme is made of.
+type CorePalette struct {
+	Primary, Secondary, Tertiary, Neutral, NeutralVariant, Error TonalPalette
+}
+
+// NewCorePalette derives the Material 3 tonal palettes from a seed color:
+// primary has the seed's hue and at least chroma 48, secondary is a muted
+// version of it, tertiary is rotated 60 degrees, and the neutrals are nearly
+// gray with a tint of the seed's hue.
+func NewCorePalette(seed color.Color) CorePalette {
+	hct := colorspace.ToHCT(seed)
+	return CorePalette{
+		Primary:        TonalPalette{Hue: hct.Hue, Chroma: math.Max(minPrimaryChroma, hct.Chroma)},
+		Secondary:      TonalPalette{Hue: hct.Hue, Chroma: 16},
+		Tertiary:       TonalPalette{Hue: colorspace.NormalizeHue(hct.Hue + 60), Chroma: 24},
+		Neutral:        TonalPalette{Hue: hct.Hue, Chroma: 4},
+		NeutralVariant: TonalPalette{Hue: hct.Hue, Chroma: 8},
+		Error:          TonalPalette{Hue: 25, Chroma: 84},
+	}
+}
+
+// palettes lists the tonal palettes with the role names of their tones
+func (p CorePalette) palettes() []struct {
+	name  string
+	tonal TonalPalette
+} {
+	return []struct {
+		name  string
+		tonal TonalPalette
+	}{
+		{"primary", p.Primary},
+		{"secondary", p.Secondary},
+		{"tertiary", p.Tertiary},
+		{"neutral", p.Neutral},
+		{"neutral-variant", p.NeutralVariant},
+		{"error", p.Error},
+	}
+}
+
+// TonalEntries returns every tonal palette at the MaterialTones, with roles
+// such as 'primary-40'.
+func (p CorePalette) TonalEntries() []parsepalette.Entry {
+	var entries []parsepalette.Entry
+	for _, palette := range p.palettes() {
+		for _, tone := range MaterialTones {
+			entries = append(entries, parsepalette.Entry{
+				Color: palette.tonal.Tone(tone),
+				Role:  fmt.Sprintf("%v-%v", palette.name, tone),
+			})
+		}
+	}
+	return entries
+}
+
+// materialPalette selects one of the tonal palettes of a CorePalette
+type materialPalette int
+
+const (
+	primaryPalette materialPalette = iota
+	secondaryPalette
+	tertiaryPalette
+	neutralPalette
+	neutralVariantPalette
+	errorPalette
+)
+
+// materialRoles are the color roles of a Material 3 scheme with the tone they
+// take in the light and in the dark scheme
+var materialRoles = []struct {
+	role        string
+	palette     materialPalette
+	light, dark float64
+}{
+	{"primary", primaryPalette, 40, 80},
+	{"on-primary", primaryPalette, 100, 20},
+	{"primary-container", primaryPalette, 90, 30},
+	{"on-primary-container", primaryPalette, 10, 90},
+	{"secondary", secondaryPalette, 40, 80},
+	{"on-secondary", secondaryPalette, 100, 20},
+	{"secondary-container", secondaryPalette, 90, 30},
+	{"on-secondary-container", secondaryPalette, 10, 90},
+	{"tertiary", tertiaryPalette, 40, 80},
+	{"on-tertiary", tertiaryPalette, 100, 20},
+	{"tertiary-container", tertiaryPalette, 90, 30},
+	{"on-tertiary-container", tertiaryPalette, 10, 90},
+	{"error", errorPalette, 40, 80},
+	{"on-error", errorPalette, 100, 20},
+	{"error-container", errorPalette, 90, 30},
+	{"on-error-container", errorPalette, 10, 80},
+	{"background", neutralPalette, 99, 10},
+	{"on-background", neutralPalette, 10, 90},
+	{"surface", neutralPalette, 99, 10},
+	{"on-surface", neutralPalette, 10, 90},
+	{"surface-variant", neutralVariantPalette, 90, 30},
+	{"on-surface-variant", neutralVariantPalette, 30, 80},
+	{"outline", neutralVariantPalette, 50, 60},
+	{"outline-variant", neutralVariantPalette, 80, 30},
+	{"shadow", neutralPalette, 0, 0},
+	{"scrim", neutralPalette, 0, 0},
+	{"inverse-surface", neutralPalette, 20, 90},
+	{"inverse-on-surface", neutralPalette, 95, 20},
+	{"inverse-primary", primaryPalette, 80, 40},
+}
+
+func (p CorePalette) tonal(palette materialPalette) TonalPalette {
+	switch palette {
+	case primaryPalette:
+		return p.Primary
+	case secondaryPalette:
+		return p.Secondary
+	case tertiaryPalette:
+		return p.Tertiary
+	case neutralPalette:
+		return p.Neutral
+	case neutralVariantPalette:
+		return p.NeutralVariant
+	default:
+		return p.Error
+	}
+}
+
+// Scheme returns the Material 3 color roles of the light or dark scheme, such
+// as 'primary', 'on-primary' and 'surface', as palette entries in that order.
+func (p CorePalette) Scheme(theme Theme) []parsepalette.Entry {
+	entries := make([]parsepalette.Entry, 0, len(materialRoles))
+	for _, r := range materialRoles {
+		tone := r.light
+		if theme == ThemeDark {
+			tone = r.dark
+		}
+		entries = append(entries, parsepalette.Entry{Color: p.tonal(r.palette).Tone(tone), Role: r.role})
+	}
+	return entries
+}
+
+// SeedColor picks the color a Material 3 scheme is derived from among the
+// colors ExtractPalette found in an image, most common first: the most common
+// color with enough chroma to give the scheme a hue, or the most common color
+// if all of them are gray.
+func SeedColor(palette color.Palette) color.RGBA {
+	for _, c := range palette {
+		if colorspace.ToHCT(c).Chroma >= minSeedChroma {
+			return color.RGBAModel.Convert(c).(color.RGBA)
+		}
+	}
+	if len(palette) == 0 {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBAModel.Convert(palette[0]).(color.RGBA)
+}
@@ -0,0 +1,130 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_NewCorePalette(t *testing.T) {
+	seed := color.RGBA{0x67, 0x50, 0xa4, 0xff}
+	core := NewCorePalette(seed)
+
+	seedHue := colorspace.ToHCT(seed).Hue
+	if math.Abs(core.Primary.Hue-seedHue) > 1e-9 || core.Primary.Chroma < minPrimaryChroma {
+		t.Errorf("Expected primary palette of hue %.2f and chroma at least %v, got %+v",
+			seedHue, minPrimaryChroma, core.Primary)
+	}
+	if expected := colorspace.NormalizeHue(seedHue + 60); math.Abs(core.Tertiary.Hue-expected) > 1e-9 {
+		t.Errorf("Expected tertiary hue %.2f, got %.2f", expected, core.Tertiary.Hue)
+	}
+
+	for _, tone := range MaterialTones {
+		got := colorspace.ToHCT(core.Primary.Tone(tone)).Tone
+		if math.Abs(got-tone) > 0.5 {
+			t.Errorf("Expected primary tone %v, got %.2f", tone, got)
+		}
+	}
+
+	entries := core.TonalEntries()
+	if expected := 6 * len(MaterialTones); len(entries) != expected {
+		t.Fatalf("Expected %v tonal entries, got %v", expected, len(entries))
+	}
+	if entries[4].Role != "primary-40" {
+		t.Errorf("Expected role 'primary-40', got '%v'", entries[4].Role)
+	}
+}
+
+func Test_CorePaletteScheme(t *testing.T) {
+	core := NewCorePalette(color.RGBA{0x1e, 0x66, 0xf5, 0xff})
+
+	tests := []struct {
+		theme    Theme
+		role     string
+		expected float64
+	}{
+		{ThemeLight, "primary", 40},
+		{ThemeLight, "on-primary", 100},
+		{ThemeLight, "surface", 99},
+		{ThemeDark, "primary", 80},
+		{ThemeDark, "on-primary-container", 90},
+		{ThemeDark, "background", 10},
+	}
+	for _, tt := range tests {
+		scheme := core.Scheme(tt.theme)
+		found := false
+		for _, e := range scheme {
+			if e.Role != tt.role {
+				continue
+			}
+			found = true
+			if got := colorspace.ToHCT(e.Color).Tone; math.Abs(got-tt.expected) > 0.5 {
+				t.Errorf("Expected %v %v to have tone %v, got %.2f", tt.theme, tt.role, tt.expected, got)
+			}
+		}
+		if !found {
+			t.Errorf("Expected %v scheme to have role '%v'", tt.theme, tt.role)
+		}
+	}
+
+	for _, theme := range []Theme{ThemeLight, ThemeDark} {
+		if got := PaletteTheme(core.Scheme(theme)); got != theme {
+			t.Errorf("Expected the %v scheme to be judged %v, got %v", theme, theme, got)
+		}
+	}
+}
+
+func Test_CorePaletteSchemeRoundTrip(t *testing.T) {
+	core := NewCorePalette(color.RGBA{0x67, 0x50, 0xa4, 0xff})
+
+	for _, theme := range []Theme{ThemeLight, ThemeDark} {
+		scheme := core.Scheme(theme)
+		path := filepath.Join(t.TempDir(), "scheme.json")
+		if err := parsepalette.SaveDocument(path, &parsepalette.Document{Entries: scheme}, parsepalette.SaveOptions{}); err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+		doc, err := parsepalette.ParseDocument(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+
+		// roles sharing a color, like background and surface, all survive
+		adjusted := AdjustEntries(doc.Entries, Adjustment{Hue: 30})
+		if len(adjusted) != len(scheme) {
+			t.Fatalf("Expected %v %v roles after reading back, got %v", len(scheme), theme, len(adjusted))
+		}
+		for i := range scheme {
+			if adjusted[i].Role != scheme[i].Role || doc.Entries[i].Color != scheme[i].Color {
+				t.Errorf("Expected %v role %v %v, got %v %v", theme, i, scheme[i], adjusted[i].Role, doc.Entries[i].Color)
+			}
+		}
+
+		if issues := Lint(doc.Entries, DefaultLintOptions()); len(issues) > 0 {
+			t.Errorf("Expected the %v scheme to lint clean, got %v", theme, issues)
+		}
+	}
+}
+
+func Test_SeedColor(t *testing.T) {
+	gray := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	blue := color.RGBA{0x1e, 0x66, 0xf5, 0xff}
+
+	tests := []struct {
+		palette  color.Palette
+		expected color.RGBA
+	}{
+		{color.Palette{gray, blue}, blue},
+		{color.Palette{blue, gray}, blue},
+		{color.Palette{gray}, gray},
+		{color.Palette{}, color.RGBA{0, 0, 0, 0xff}},
+	}
+	for _, tt := range tests {
+		if got := SeedColor(tt.palette); got != tt.expected {
+			t.Errorf("Expected seed %v from %v, got %v", tt.expected, tt.palette, got)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package palettetools
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// SortMode selects the order in which the colors of a palette are arranged.
+type SortMode string
+
+const (
+	SortFrequency SortMode = "frequency"
+	SortLightness SortMode = "lightness"
+	SortHue       SortMode = "hue"
+	SortSpectral  SortMode = "spectral"
+)
+
+// maxTwoOptPasses bounds the improvement passes of the spectral ordering,
+// each pass is O(n^2) in the palette size.
+const maxTwoOptPasses = 50
+
+// ParseSortMode validates a sort mode name given on the command line.
+func ParseSortMode(name string) (SortMode, error) {
+	switch mode := SortMode(name); mode {
+	case SortFrequency, SortLightness, SortHue, SortSpectral:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid sort mode '%v' (expected frequency, lightness, hue or spectral)", name)
+	}
+}
+
+// SortPalette returns a copy of the palette arranged by mode.
+// SortFrequency keeps the input order, which is the order ExtractPalette
+// produces (most common color first).
+func SortPalette(palette color.Palette, mode SortMode) color.Palette {
+	order := SortOrder(palette, mode)
+	sorted := make(color.Palette, len(order))
+	for i, idx := range order {
+		sorted[i] = palette[idx]
+	}
+	return sorted
+}
+
+// SortEntries returns a copy of the palette entries arranged by mode, keeping
+// each color's metadata attached to it.
+func SortEntries(entries []parsepalette.Entry, mode SortMode) []parsepalette.Entry {
+	palette := make(color.Palette, len(entries))
+	for i, e := range entries {
+		palette[i] = e.Color
+	}
+
+	sorted := make([]parsepalette.Entry, 0, len(entries))
+	for _, idx := range SortOrder(palette, mode) {
+		sorted = append(sorted, entries[idx])
+	}
+	return sorted
+}
+
+// SortOrder returns the indices of the palette colors in the order given by
+// mode, so callers can rearrange data that runs parallel to the palette.
+func SortOrder(palette color.Palette, mode SortMode) []int {
+	order := make([]int, len(palette))
+	for i := range order {
+		order[i] = i
+	}
+
+	labs := make([]colorspace.OKLab, len(palette))
+	for i, c := range palette {
+		labs[i] = colorspace.ToOKLab(c)
+	}
+
+	switch mode {
+	case SortLightness:
+		sort.SliceStable(order, func(i, j int) bool {
+			return labs[order[i]].L < labs[order[j]].L
+		})
+	case SortHue:
+		sort.SliceStable(order, func(i, j int) bool {
+			return hueLess(labs[order[i]].LCh(), labs[order[j]].LCh())
+		})
+	case SortSpectral:
+		order = spectralOrder(labs)
+	}
+
+	return order
+}
+
+// hueLess orders grays first by lightness, followed by the chromatic colors
+// around the hue wheel, using lightness to break hue ties.
+func hueLess(a, b colorspace.OKLCh) bool {
+	aGray, bGray := a.IsAchromatic(), b.IsAchromatic()
+	if aGray != bGray {
+		return aGray
+	}
+	if aGray || a.H == b.H {
+		return a.L < b.L
+	}
+	return a.H < b.H
+}
+
+// spectralOrder finds a short path through all colors in OKLab so that
+// neighbouring swatches are perceptually close. The path starts at the darkest
+// color, is built greedily by nearest neighbour and then refined with 2-opt.
+func spectralOrder(labs []colorspace.OKLab) []int {
+	n := len(labs)
+	if n == 0 {
+		return []int{}
+	}
+
+	start := 0
+	for i := range labs {
+		if labs[i].L < labs[start].L {
+			start = i
+		}
+	}
+
+	visited := make([]bool, n)
+	order := make([]int, 0, n)
+	order = append(order, start)
+	visited[start] = true
+
+	for len(order) < n {
+		last := labs[order[len(order)-1]]
+		next := -1
+		nextDist := 0.0
+		for i := range labs {
+			if visited[i] {
+				continue
+			}
+			d := colorspace.DeltaEOK(last, labs[i])
+			if next == -1 || d < nextDist {
+				next, nextDist = i, d
+			}
+		}
+		order = append(order, next)
+		visited[next] = true
+	}
+
+	dist := func(i, j int) float64 {
+		return colorspace.DeltaEOK(labs[order[i]], labs[order[j]])
+	}
+
+	for pass := 0; pass < maxTwoOptPasses; pass++ {
+		improved := false
+		for i := 1; i < n-1; i++ {
+			for j := i + 1; j < n; j++ {
+				before := dist(i-1, i)
+				after := dist(i-1, j)
+				if j+1 < n {
+					before += dist(j, j+1)
+					after += dist(i, j+1)
+				}
+				if after < before-1e-12 {
+					for l, r := i, j; l < r; l, r = l+1, r-1 {
+						order[l], order[r] = order[r], order[l]
+					}
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,68 @@
+package palettetools
+
+import (
+	"image/color"
+	"testing"
+)
+
+func Test_ParseSortMode(t *testing.T) {
+	for _, name := range []string{"frequency", "lightness", "hue", "spectral"} {
+		if _, err := ParseSortMode(name); err != nil {
+			t.Errorf("Expected no error for %q, got: %v", name, err)
+		}
+	}
+	if _, err := ParseSortMode("random"); err == nil {
+		t.Errorf("Expected error for invalid sort mode, got none")
+	}
+}
+
+func Test_SortPalette(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	black := color.RGBA{0, 0, 0, 255}
+	red := color.RGBA{255, 0, 0, 255}
+	green := color.RGBA{0, 255, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+
+	palette := color.Palette{white, red, black, blue, green}
+
+	tests := []struct {
+		mode     SortMode
+		expected color.Palette
+	}{
+		{SortFrequency, color.Palette{white, red, black, blue, green}},
+		{SortLightness, color.Palette{black, blue, red, green, white}},
+		{SortHue, color.Palette{black, white, red, green, blue}},
+	}
+
+	for _, tt := range tests {
+		sorted := SortPalette(palette, tt.mode)
+		for i := range tt.expected {
+			if sorted[i] != tt.expected[i] {
+				t.Errorf("Mode %v: expected %v, got %v", tt.mode, tt.expected, sorted)
+				break
+			}
+		}
+	}
+}
+
+func Test_SortPaletteSpectral(t *testing.T) {
+	// a ramp from black to white, shuffled
+	var ramp color.Palette
+	for i := 0; i < 8; i++ {
+		v := uint8(i * 36)
+		ramp = append(ramp, color.RGBA{v, v, v, 255})
+	}
+	shuffled := color.Palette{ramp[5], ramp[0], ramp[7], ramp[2], ramp[4], ramp[1], ramp[6], ramp[3]}
+
+	sorted := SortPalette(shuffled, SortSpectral)
+
+	if len(sorted) != len(ramp) {
+		t.Fatalf("Expected same palette len, got: expected=%v, actual=%v", len(ramp), len(sorted))
+	}
+	for i := range ramp {
+		if sorted[i] != ramp[i] {
+			t.Errorf("Expected ramp order %v, got %v", ramp, sorted)
+			break
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package palettetools
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+// Theme tells whether a palette is meant for a light or a dark background.
+type Theme string
+
+const (
+	ThemeLight Theme = "light"
+	ThemeDark  Theme = "dark"
+)
+
+const (
+	// backgroundRole is the role of the entry a palette's theme is judged by
+	backgroundRole = "background"
+	// mirrorSearchSteps bounds the binary search for a mirrored lightness
+	mirrorSearchSteps = 24
+)
+
+// ParseTheme validates a theme name given on the command line.
+func ParseTheme(name string) (Theme, error) {
+	switch theme := Theme(name); theme {
+	case ThemeLight, ThemeDark:
+		return theme, nil
+	default:
+		return "", fmt.Errorf("invalid theme '%v' (expected light or dark)", name)
+	}
+}
+
+// MirrorLightness mirrors the lightness of a color so that its WCAG contrast
+// against black becomes its contrast against white, keeping its OKLCh hue and
+// as much of its chroma as fits the sRGB gamut. Black becomes white, and the
+// contrast ratio between any two colors is the same after mirroring both.
+func MirrorLightness(c color.Color) color.RGBA {
+	target := mirroredLuminance(colorspace.RelativeLuminance(c))
+	lch := colorspace.ToOKLCh(c)
+
+	// luminance grows with OKLCh lightness at a fixed hue and chroma
+	low, high := 0.0, 1.0
+	for i := 0; i < mirrorSearchSteps; i++ {
+		lch.L = (low + high) / 2
+		if colorspace.RelativeLuminance(lch.ToRGBA()) < target {
+			low = lch.L
+		} else {
+			high = lch.L
+		}
+	}
+
+	// pick whichever bound is closer after rounding to 8 bits
+	lowColor, highColor := colorspace.OKLCh{L: low, C: lch.C, H: lch.H}.ToRGBA(),
+		colorspace.OKLCh{L: high, C: lch.C, H: lch.H}.ToRGBA()
+	if math.Abs(colorspace.RelativeLuminance(lowColor)-target) <
+		math.Abs(colorspace.RelativeLuminance(highColor)-target) {
+		return lowColor
+	}
+	return highColor
+}
+
+// mirroredLuminance maps a relative luminance y to the luminance whose contrast
+// against white equals the contrast of y against black, which inverts the
+// WCAG contrast ratio (y + 0.05) / (y' + 0.05) of any pair of colors
+func mirroredLuminance(y float64) float64 {
+	return 0.05*1.05/(y+0.05) - 0.05
+}
+
+// PaletteTheme judges whether the entries form a light or a dark palette by
+// the lightness of the entry with the 'background' role, or else by the
+// average lightness of all entries, weighted by their coverage if they have it.
+func PaletteTheme(entries []parsepalette.Entry) Theme {
+	for _, e := range entries {
+		if e.Role == backgroundRole {
+			return themeOfLightness(colorspace.ToOKLCh(e.Color).L)
+		}
+	}
+
+	var sum, totalWeight float64
+	for _, e := range entries {
+		weight := e.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		sum += weight * colorspace.ToOKLCh(e.Color).L
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return ThemeLight
+	}
+	return themeOfLightness(sum / totalWeight)
+}
+
+func themeOfLightness(lightness float64) Theme {
+	if lightness < 0.5 {
+		return ThemeDark
+	}
+	return ThemeLight
+}
+
+// ThemeVariant returns the entries as a palette of the given theme, mirroring
+// the lightness of every color with MirrorLightness when the palette is of the
+// other theme and returning a copy unchanged otherwise. Metadata is kept, so
+// the 'background' entry of a dark palette is the background of its light
+// variant.
+func ThemeVariant(entries []parsepalette.Entry, theme Theme) []parsepalette.Entry {
+	variant := make([]parsepalette.Entry, len(entries))
+	copy(variant, entries)
+	if PaletteTheme(entries) == theme {
+		return variant
+	}
+
+	for i := range variant {
+		variant[i].Color = MirrorLightness(variant[i].Color)
+	}
+	return variant
+}
@@ -0,0 +1,81 @@
+package palettetools
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+	"github.com/VannRR/color-schemorator/parsepalette"
+)
+
+func Test_MirrorLightness(t *testing.T) {
+	tests := []struct {
+		input    color.RGBA
+		expected color.RGBA
+	}{
+		{color.RGBA{0x00, 0x00, 0x00, 0xff}, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{color.RGBA{0xff, 0xff, 0xff, 0xff}, color.RGBA{0x00, 0x00, 0x00, 0xff}},
+	}
+	for _, tt := range tests {
+		if got := MirrorLightness(tt.input); got != tt.expected {
+			t.Errorf("Expected %v to mirror to %v, got %v", tt.input, tt.expected, got)
+		}
+	}
+
+	base := color.RGBA{0x1e, 0x1e, 0x2e, 0xff}
+	text := color.RGBA{0xcd, 0xd6, 0xf4, 0xff}
+	mirroredBase, mirroredText := MirrorLightness(base), MirrorLightness(text)
+
+	if colorspace.RelativeLuminance(mirroredBase) < colorspace.RelativeLuminance(mirroredText) {
+		t.Errorf("Expected mirrored base %v to be lighter than mirrored text %v", mirroredBase, mirroredText)
+	}
+	contrast, mirroredContrast := colorspace.ContrastRatio(base, text), colorspace.ContrastRatio(mirroredBase, mirroredText)
+	if math.Abs(contrast-mirroredContrast) > 0.2 {
+		t.Errorf("Expected contrast ratio %.2f to be kept, got %.2f", contrast, mirroredContrast)
+	}
+
+	textHue, mirroredHue := colorspace.ToOKLCh(text).H, colorspace.ToOKLCh(mirroredText).H
+	if diff := math.Abs(mirroredHue - textHue); math.Min(diff, 360-diff) > 5 {
+		t.Errorf("Expected hue %.1f to be kept, got %.1f", textHue, mirroredHue)
+	}
+
+	if got := MirrorLightness(MirrorLightness(base)); colorspace.DeltaEOK(colorspace.ToOKLab(got), colorspace.ToOKLab(base)) > 0.01 {
+		t.Errorf("Expected mirroring twice to give back %v, got %v", base, got)
+	}
+}
+
+func Test_ThemeVariant(t *testing.T) {
+	mocha, err := parsepalette.BuiltinDocument("catppuccin-mocha")
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if theme := PaletteTheme(mocha.Entries); theme != ThemeDark {
+		t.Fatalf("Expected mocha to be dark, got %v", theme)
+	}
+
+	light := ThemeVariant(mocha.Entries, ThemeLight)
+	if theme := PaletteTheme(light); theme != ThemeLight {
+		t.Errorf("Expected light variant to be light, got %v", theme)
+	}
+	for i := range light {
+		if light[i].Name != mocha.Entries[i].Name || light[i].Role != mocha.Entries[i].Role {
+			t.Errorf("Expected metadata of %v to be kept, got %v", mocha.Entries[i], light[i])
+		}
+	}
+
+	dark := ThemeVariant(mocha.Entries, ThemeDark)
+	for i := range dark {
+		if dark[i] != mocha.Entries[i] {
+			t.Errorf("Expected dark palette to be kept as is, got %v for %v", dark[i], mocha.Entries[i])
+		}
+	}
+
+	unroled := []parsepalette.Entry{
+		{Color: color.RGBA{0xf0, 0xf0, 0xf0, 0xff}, Weight: 0.1},
+		{Color: color.RGBA{0x10, 0x10, 0x10, 0xff}, Weight: 0.9},
+	}
+	if theme := PaletteTheme(unroled); theme != ThemeDark {
+		t.Errorf("Expected mostly dark palette to be dark, got %v", theme)
+	}
+}
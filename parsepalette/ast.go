@@ -0,0 +1,333 @@
+package parsepalette
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/VannRR/color-schemorator/utility"
+)
+
+// NodeKind is the kind of a line of a text palette.
+type NodeKind int
+
+const (
+	BlankNode   NodeKind = iota // an empty line
+	CommentNode                 // a line with only a '//' comment
+	ColorNode                   // a '#RGB' or '#RRGGBB' color
+	RampNode                    // a '#a -> #b : <steps> [space]' ramp
+	IncludeNode                 // an '@include <path>' directive
+	ExcludeNode                 // an '@exclude <hex>' directive
+)
+
+const commentMarker = "//"
+
+// Node is one line of a text palette, split into parts that write back to the
+// exact line: Indent, Code and Gap are the leading whitespace, the line without
+// its comment and the whitespace before the comment (or the end of the line),
+// and Comment is the text after '//' when HasComment is set.
+type Node struct {
+	Kind       NodeKind
+	Indent     string
+	Code       string
+	Gap        string
+	Comment    string
+	HasComment bool
+	// Color is the color of a ColorNode or ExcludeNode
+	Color color.RGBA
+	// err is the problem found on the line, if any
+	err *ParseError
+}
+
+// File is the syntax tree of a text palette, one Node per line. Its Bytes are
+// the text it was parsed from until it is changed, so that palettes can be
+// rewritten keeping their comments.
+type File struct {
+	Path  string
+	Nodes []Node
+	// NoFinalNewline is set when the last line has no line break
+	NoFinalNewline bool
+}
+
+// ReadFile reads and parses a text palette file. JSON and built-in palettes
+// have no text syntax and are refused.
+func ReadFile(path string) (*File, error) {
+	if isBuiltinPath(path) || strings.ToLower(filepath.Ext(path)) == ".json" {
+		return nil, fmt.Errorf("%v: not a text palette", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer file.Close()
+
+	if err := utility.ValidateFileSize(file, "Input palette", maxPaletteFileSizeMB); err != nil {
+		return nil, err
+	}
+
+	src, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return ParseFile(path, src)
+}
+
+// ParseFile parses the text of a palette into a File. Included files are not
+// read. Invalid lines are returned as ParseErrors, along with the File.
+func ParseFile(path string, src []byte) (*File, error) {
+	f := parseFile(path, src)
+
+	parseErrs := &ParseErrors{}
+	for _, n := range f.Nodes {
+		if n.err != nil {
+			parseErrs.add(n.err)
+		}
+	}
+
+	if len(parseErrs.Errors) > 0 {
+		return f, parseErrs
+	}
+	return f, nil
+}
+
+// parseFile splits the text of a palette into one Node per line, keeping the
+// problem found on each line in its node
+func parseFile(path string, src []byte) *File {
+	f := &File{Path: path}
+	text := string(src)
+	if text == "" {
+		return f
+	}
+	if strings.HasSuffix(text, "\n") {
+		text = strings.TrimSuffix(text, "\n")
+	} else {
+		f.NoFinalNewline = true
+	}
+
+	for i, raw := range strings.Split(text, "\n") {
+		f.Nodes = append(f.Nodes, parseNode(sourceLine{Path: path, Number: i + 1}, raw))
+	}
+	return f
+}
+
+// codeLines returns the lines of a freshly parsed palette that hold a color,
+// ramp or directive, with their position in the file and their node
+func (f *File) codeLines() []sourceLine {
+	var lines []sourceLine
+	for i, n := range f.Nodes {
+		if n.Code != "" {
+			lines = append(lines, sourceLine{Path: f.Path, Number: i + 1, Column: len(n.Indent) + 1, Text: n.Code, node: n})
+		}
+	}
+	return lines
+}
+
+// parseNode splits a line into a Node, recording invalid colors, ramps and
+// directives in its err. It is the grammar of text palettes, every reader of
+// palette lines goes through it.
+func parseNode(line sourceLine, raw string) Node {
+	var node Node
+	code, comment, hasComment := strings.Cut(raw, commentMarker)
+	node.Comment, node.HasComment = comment, hasComment
+
+	trimmedLeft := strings.TrimLeftFunc(code, unicode.IsSpace)
+	node.Indent = code[:len(code)-len(trimmedLeft)]
+	node.Code = strings.TrimRightFunc(trimmedLeft, unicode.IsSpace)
+	node.Gap = trimmedLeft[len(node.Code):]
+
+	line.Column, line.Text = len(node.Indent)+1, node.Code
+	if node.Code == "" {
+		if hasComment {
+			node.Kind = CommentNode
+		}
+		return node
+	}
+
+	if argument, isExclude := directiveArgument(node.Code, excludeDirective); isExclude {
+		node.Kind = ExcludeNode
+		c, err := parseHexColor(argument)
+		if err != nil {
+			node.err = line.errorAt(argumentOffset(node.Code, argument), argument, err.Error())
+		} else {
+			node.Color = c.(color.RGBA)
+		}
+	} else if argument, isInclude := directiveArgument(node.Code, includeDirective); isInclude {
+		node.Kind = IncludeNode
+		if argument == "" {
+			node.err = line.errorAt(len(node.Code), "", fmt.Sprintf("missing path after %v", includeDirective))
+		}
+	} else if strings.HasPrefix(node.Code, "@") {
+		directive := strings.Fields(node.Code)[0]
+		node.err = line.errorAt(0, directive, fmt.Sprintf("unknown directive '%v'", truncateString(directive, 30)))
+	} else if isRampLine(node.Code) {
+		node.Kind = RampNode
+		_, node.err = parseRamp(line, 0)
+	} else {
+		node.Kind = ColorNode
+		c, err := parseHexColor(node.Code)
+		if err != nil {
+			node.err = line.errorAt(0, node.Code, err.Error())
+		} else {
+			node.Color = c.(color.RGBA)
+		}
+	}
+	return node
+}
+
+// colorLine is a line holding a single color, as contributed by an included
+// JSON or built-in palette
+func colorLine(path string, c color.RGBA) sourceLine {
+	hex := FormatHexColor(c)
+	return sourceLine{Path: path, Text: hex, node: Node{Kind: ColorNode, Code: hex, Color: c}}
+}
+
+// String returns the line the node was parsed from, or its formatted form.
+func (n Node) String() string {
+	line := n.Indent + n.Code + n.Gap
+	if n.HasComment {
+		line += commentMarker + n.Comment
+	}
+	return line
+}
+
+// Bytes returns the text of the palette.
+func (f *File) Bytes() []byte {
+	lines := make([]string, len(f.Nodes))
+	for i, n := range f.Nodes {
+		lines[i] = n.String()
+	}
+	text := strings.Join(lines, "\n")
+	if len(f.Nodes) > 0 && !f.NoFinalNewline {
+		text += "\n"
+	}
+	return []byte(text)
+}
+
+// Format rewrites the palette in its canonical form: colors as uppercase
+// '#RRGGBB', no indentation or trailing whitespace, single spaces in
+// directives and ramps, trailing comments aligned within each run of code
+// lines, no repeated or surrounding blank lines, and a final line break.
+// Color lines repeating an earlier color or a color of an earlier ramp are
+// removed, as the parser leaves them out. Their comment moves to the first
+// line of the color if it has none, or else stays as a comment line. Colors of
+// included files are not known and kept. The File must have parsed without
+// errors.
+func (f *File) Format() {
+	firstLine := make(map[color.RGBA]int)
+	var nodes []Node
+	for _, n := range f.Nodes {
+		n.Indent, n.Gap = "", ""
+		n.Comment = strings.TrimRightFunc(n.Comment, unicode.IsSpace)
+		n.Code = formatCode(n)
+
+		if n.Kind == RampNode {
+			rampColors, _ := parseRamp(sourceLine{Text: n.Code}, 0)
+			for _, c := range rampColors {
+				if _, exists := firstLine[c.(color.RGBA)]; !exists {
+					firstLine[c.(color.RGBA)] = len(nodes)
+				}
+			}
+		} else if n.Kind == ColorNode {
+			if first, exists := firstLine[n.Color]; exists {
+				switch {
+				case !n.HasComment:
+					continue
+				case !nodes[first].HasComment:
+					nodes[first].Comment, nodes[first].HasComment = n.Comment, true
+					continue
+				default:
+					n = Node{Kind: CommentNode, Comment: n.Comment, HasComment: true}
+				}
+			} else {
+				firstLine[n.Color] = len(nodes)
+			}
+		}
+
+		if n.Kind == BlankNode && (len(nodes) == 0 || nodes[len(nodes)-1].Kind == BlankNode) {
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) > 0 && nodes[len(nodes)-1].Kind == BlankNode {
+		nodes = nodes[:len(nodes)-1]
+	}
+
+	f.Nodes, f.NoFinalNewline = nodes, false
+	f.alignComments()
+}
+
+// formatCode returns the canonical code of a node parsed without errors
+func formatCode(n Node) string {
+	switch n.Kind {
+	case ColorNode:
+		return FormatHexColor(n.Color)
+	case ExcludeNode:
+		return excludeDirective + " " + FormatHexColor(n.Color)
+	case IncludeNode:
+		argument, _ := directiveArgument(n.Code, includeDirective)
+		return includeDirective + " " + argument
+	case RampNode:
+		arrowIndex := strings.Index(n.Code, rampArrow)
+		sepIndex := strings.Index(n.Code, rampSeparator)
+		from, _ := parseHexColor(n.Code[:arrowIndex])
+		to, _ := parseHexColor(n.Code[arrowIndex+len(rampArrow) : sepIndex])
+		fields := strings.Fields(n.Code[sepIndex+len(rampSeparator):])
+		steps, _ := strconv.Atoi(fields[0])
+		fields[0] = strconv.Itoa(steps)
+		if len(fields) == 2 {
+			fields[1] = strings.ToLower(fields[1])
+		}
+		return fmt.Sprintf("%v %v %v %v %v", FormatHexColor(from), rampArrow, FormatHexColor(to),
+			rampSeparator, strings.Join(fields, " "))
+	default:
+		return n.Code
+	}
+}
+
+// alignComments lines up the trailing comments of each run of code lines one
+// space after the longest code with a comment
+func (f *File) alignComments() {
+	for start := 0; start < len(f.Nodes); {
+		end := start
+		width := 0
+		for ; end < len(f.Nodes) && f.Nodes[end].Code != ""; end++ {
+			if f.Nodes[end].HasComment {
+				width = max(width, len(f.Nodes[end].Code))
+			}
+		}
+		for i := start; i < end; i++ {
+			if f.Nodes[i].HasComment {
+				f.Nodes[i].Gap = strings.Repeat(" ", width-len(f.Nodes[i].Code)+1)
+			}
+		}
+		start = end + 1
+	}
+}
+
+// SortColors reorders each run of consecutive color lines, carrying their
+// comments along. order returns the new order of a run's colors as indices,
+// like palettetools.SortOrder. Comment lines, blank lines and other lines end
+// a run and stay where they are.
+func (f *File) SortColors(order func(palette color.Palette) []int) {
+	for start := 0; start < len(f.Nodes); {
+		end := start
+		var palette color.Palette
+		for ; end < len(f.Nodes) && f.Nodes[end].Kind == ColorNode; end++ {
+			palette = append(palette, f.Nodes[end].Color)
+		}
+		if len(palette) > 1 {
+			run := make([]Node, len(palette))
+			for i, idx := range order(palette) {
+				run[i] = f.Nodes[start+idx]
+			}
+			copy(f.Nodes[start:end], run)
+		}
+		start = end + 1
+	}
+}
@@ -0,0 +1,156 @@
+package parsepalette
+
+import (
+	"errors"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func Test_ParseFileRoundTrip(t *testing.T) {
+	sources := []string{
+		"",
+		"#fff\n#000000",
+		"  #ea76cb   // pink  \n\n\n// a comment\r\n\t@include  ../base.txt//x\n",
+		"@exclude #333 // gone\n#1e1e2e->#cdd6f4:  08  OKLCH\n//\n",
+	}
+
+	for _, src := range sources {
+		f, err := ParseFile("palette.txt", []byte(src))
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got error: %v", src, err)
+		}
+		if got := string(f.Bytes()); got != src {
+			t.Errorf("Expected round trip of %q, got %q", src, got)
+		}
+	}
+}
+
+func Test_ParseFileNodes(t *testing.T) {
+	f, err := ParseFile("palette.txt", []byte("  #ea76cb // pink\n\n// comment\n@include base.txt\n@exclude #333\n#000 -> #fff : 3\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	expected := []NodeKind{ColorNode, BlankNode, CommentNode, IncludeNode, ExcludeNode, RampNode}
+	if len(f.Nodes) != len(expected) {
+		t.Fatalf("Expected %v nodes, got %v", len(expected), len(f.Nodes))
+	}
+	for i, kind := range expected {
+		if f.Nodes[i].Kind != kind {
+			t.Errorf("Expected node %v to be of kind %v, got %v", i, kind, f.Nodes[i].Kind)
+		}
+	}
+	if pink := f.Nodes[0]; pink.Color != (color.RGBA{0xea, 0x76, 0xcb, 0xff}) || pink.Indent != "  " ||
+		pink.Code != "#ea76cb" || pink.Comment != " pink" {
+		t.Errorf("Expected pink color node with its indent and comment, got %+v", pink)
+	}
+}
+
+func Test_ParseFileErrors(t *testing.T) {
+	_, err := ParseFile("palette.txt", []byte("#ea76cb\n  #12345g // bad\n@import x\n#000 -> #fff : 1\n@include\n"))
+
+	var parseErrs *ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("Expected ParseErrors, got %v", err)
+	}
+	expected := []struct{ line, column int }{{2, 3}, {3, 1}, {4, 16}, {5, 9}}
+	if len(parseErrs.Errors) != len(expected) {
+		t.Fatalf("Expected %v errors, got %v", len(expected), err)
+	}
+	for i, pos := range expected {
+		if got := parseErrs.Errors[i]; got.Line != pos.line || got.Column != pos.column {
+			t.Errorf("Expected error at %v:%v, got %v", pos.line, pos.column, got)
+		}
+	}
+}
+
+func Test_FileFormat(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{
+			src:      "\n\n  #ea76cb\n#FFF   \n\n\n\n#1e1e2e",
+			expected: "#EA76CB\n#FFFFFF\n\n#1E1E2E\n",
+		},
+		{
+			src: "@include   base.txt   // base colors\n#abc // light\n#1e1e2e->#cdd6f4:08   OKLCH //ramp\n" +
+				"// section\n#000 // black\n",
+			expected: "@include base.txt            // base colors\n" +
+				"#AABBCC                      // light\n" +
+				"#1E1E2E -> #CDD6F4 : 8 oklch //ramp\n" +
+				"// section\n#000000 // black\n",
+		},
+		{
+			src:      "#fff\n#ea76cb\n#FFFFFF // white\n#EA76CB\n#ea76cb // pink again\n@exclude  #333\n",
+			expected: "#FFFFFF // white\n#EA76CB // pink again\n@exclude #333333\n",
+		},
+		{
+			src:      "#000 // black\n#000000 // also black\n",
+			expected: "#000000 // black\n// also black\n",
+		},
+		{
+			src:      "#000 -> #fff : 3\n#FFFFFF // white\n#ea76cb\n",
+			expected: "#000000 -> #FFFFFF : 3 // white\n#EA76CB\n",
+		},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFile("palette.txt", []byte(tt.src))
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got error: %v", tt.src, err)
+		}
+		f.Format()
+		if got := string(f.Bytes()); got != tt.expected {
+			t.Errorf("Expected %q to format to %q, got %q", tt.src, tt.expected, got)
+		}
+
+		again, err := ParseFile("palette.txt", f.Bytes())
+		if err != nil {
+			t.Fatalf("Expected formatted palette to parse, got error: %v", err)
+		}
+		if again.Format(); string(again.Bytes()) != tt.expected {
+			t.Errorf("Expected formatting %q to change nothing, got %q", tt.expected, again.Bytes())
+		}
+	}
+}
+
+func Test_FileSortColors(t *testing.T) {
+	f, err := ParseFile("palette.txt", []byte("#333333 // c\n#111111 // a\n// keep\n#222222\n#000000\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	reverse := func(palette color.Palette) []int {
+		order := make([]int, len(palette))
+		for i := range order {
+			order[i] = len(palette) - 1 - i
+		}
+		return order
+	}
+	f.SortColors(reverse)
+
+	expected := "#111111 // a\n#333333 // c\n// keep\n#000000\n#222222\n"
+	if got := string(f.Bytes()); got != expected {
+		t.Errorf("Expected sorted palette %q, got %q", expected, got)
+	}
+}
+
+func Test_ReadFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"palette.txt":  "#ea76cb\n",
+		"palette.json": `{"entries": [{"hex": "#ea76cb"}, {"hex": "#000000"}]}`,
+	})
+
+	f, err := ReadFile(filepath.Join(dir, "palette.txt"))
+	if err != nil || len(f.Nodes) != 1 {
+		t.Errorf("Expected one node, got %v (%v)", f, err)
+	}
+	for _, path := range []string{filepath.Join(dir, "palette.json"), "builtin:nord", filepath.Join(dir, "missing.txt")} {
+		if _, err := ReadFile(path); err == nil {
+			t.Errorf("Expected an error reading %v", path)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package parsepalette
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// BuiltinPrefix marks a palette path as the name of a built-in palette,
+// as in 'builtin:nord'.
+const BuiltinPrefix = "builtin:"
+
+//go:embed builtin/*.json
+var builtinFiles embed.FS
+
+// BuiltinNames returns the names of the built-in palettes in alphabetical order.
+func BuiltinNames() []string {
+	files, err := builtinFiles.ReadDir("builtin")
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(file.Name(), ".json"))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// BuiltinDocument returns the built-in palette with the given name, with or
+// without BuiltinPrefix.
+func BuiltinDocument(name string) (*Document, error) {
+	return builtinDocument(name, ParseOptions{})
+}
+
+// builtinDocument reads a built-in palette like BuiltinDocument, with the
+// color limit and duplicate handling given by the options
+func builtinDocument(name string, opts ParseOptions) (*Document, error) {
+	name = strings.ToLower(strings.TrimPrefix(name, BuiltinPrefix))
+	if !slices.Contains(BuiltinNames(), name) {
+		return nil, fmt.Errorf("unknown built-in palette '%v' (see 'csor palettes list')", truncateString(name, 30))
+	}
+
+	file, err := builtinFiles.Open(path.Join("builtin", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("could not open built-in palette: %w", err)
+	}
+	defer file.Close()
+
+	return parseJSONDocument(BuiltinPrefix+name, file, opts)
+}
+
+// isBuiltinPath reports whether the palette path names a built-in palette
+func isBuiltinPath(palettePath string) bool {
+	return strings.HasPrefix(palettePath, BuiltinPrefix)
+}
@@ -0,0 +1,80 @@
+package parsepalette
+
+import (
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_BuiltinPalettes(t *testing.T) {
+	names := BuiltinNames()
+	for _, expected := range []string{"catppuccin-latte", "catppuccin-mocha", "nord", "dracula", "pico-8", "gameboy"} {
+		found := false
+		for _, name := range names {
+			found = found || name == expected
+		}
+		if !found {
+			t.Errorf("Expected built-in palette %q in %v", expected, names)
+		}
+	}
+
+	for _, name := range names {
+		doc, err := BuiltinDocument(name)
+		if err != nil {
+			t.Errorf("Expected no error for %v, got error: %v", name, err)
+			continue
+		}
+		if doc.Name == "" || doc.Author == "" || doc.Source == "" {
+			t.Errorf("Expected name, author and source for %v, got %+v", name, doc)
+		}
+	}
+
+	if _, err := BuiltinDocument("nope"); err == nil {
+		t.Errorf("Expected error for unknown built-in palette, got none")
+	}
+}
+
+func Test_ParsePaletteBuiltin(t *testing.T) {
+	palette, err := ParsePalette("builtin:nord")
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(palette) != 16 || palette[0] != (color.RGBA{0x2e, 0x34, 0x40, 0xff}) {
+		t.Errorf("Expected the 16 Nord colors starting with #2E3440, got %v", palette)
+	}
+	if _, err := ParsePaletteWithOptions("builtin:nord", ParseOptions{MaxColors: 8}); err == nil ||
+		!strings.Contains(err.Error(), "Max amount of colors in palette is 8") {
+		t.Errorf("Expected max colors error for a built-in palette, got: %v", err)
+	}
+
+	// the test fixture is Catppuccin Latte with two extra colors
+	latte, err := ParsePalette("builtin:catppuccin-latte")
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	fixture, err := ParsePalette(testPaletteInputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(fixture) != len(latte)+2 {
+		t.Fatalf("Expected fixture to have %v colors, got %v", len(latte)+2, len(fixture))
+	}
+	for i, c := range latte {
+		if fixture[i+2] != c {
+			t.Errorf("Expected color %v, got %v", fixture[i+2], c)
+		}
+	}
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"game.txt": "@include builtin:gameboy\n@exclude #0f380f\n#ffffff\n",
+	})
+	palette, err = ParsePalette(filepath.Join(dir, "game.txt"))
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(palette) != 4 || palette[0] != (color.RGBA{0x30, 0x62, 0x30, 0xff}) {
+		t.Errorf("Expected included Game Boy palette without its darkest color, got %v", palette)
+	}
+}
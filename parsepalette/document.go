@@ -0,0 +1,171 @@
+package parsepalette
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+)
+
+// Document is a palette together with its metadata, as stored in the JSON
+// palette format.
+type Document struct {
+	Name    string
+	Author  string
+	Source  string // image the palette was extracted from, or where it comes from
+	Entries []Entry
+}
+
+// Entry is a palette color together with the metadata that palette writers
+// can record next to it.
+type Entry struct {
+	Color  color.RGBA
+	Name   string  // optional human readable name
+	Role   string  // optional role name such as 'background', used in variable names
+	Weight float64 // share of the source image covered by the color, in [0, 1]
+}
+
+// Palette returns the colors of the document's entries.
+func (doc *Document) Palette() color.Palette {
+	palette := make(color.Palette, 0, len(doc.Entries))
+	for _, e := range doc.Entries {
+		palette = append(palette, e.Color)
+	}
+	return palette
+}
+
+// documentFromColors builds a document without metadata from parsed colors
+func documentFromColors(colors []color.Color) *Document {
+	doc := &Document{Entries: make([]Entry, 0, len(colors))}
+	for _, c := range colors {
+		doc.Entries = append(doc.Entries, Entry{Color: color.RGBAModel.Convert(c).(color.RGBA)})
+	}
+	return doc
+}
+
+// jsonDocument is the JSON palette schema
+type jsonDocument struct {
+	Name    string      `json:"name,omitempty"`
+	Author  string      `json:"author,omitempty"`
+	Source  string      `json:"source,omitempty"`
+	Entries []jsonEntry `json:"entries"`
+}
+
+type jsonEntry struct {
+	Hex    string  `json:"hex"`
+	Name   string  `json:"name,omitempty"`
+	Role   string  `json:"role,omitempty"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// parseJSONDocument decodes and validates a JSON palette. Entries repeating
+// an earlier color are dropped like in text palettes unless they have a role
+// or a name not given before, so that roles sharing a color are kept, or the
+// options keep duplicates. More than the options' MaxColors colors is an error
+// unless it is 0. Problems are returned as ParseErrors reported against
+// palettePath and the entry number.
+func parseJSONDocument(palettePath string, r io.Reader, opts ParseOptions) (*Document, error) {
+	maxColors := opts.MaxColors
+	var raw jsonDocument
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, &ParseErrors{Errors: []*ParseError{{Path: palettePath,
+			Reason: "error decoding JSON palette", Err: err}}}
+	}
+
+	doc := &Document{Name: raw.Name, Author: raw.Author, Source: raw.Source}
+	parseErrs := &ParseErrors{}
+	seenColors := make(map[color.RGBA]struct{})
+	seenRoles := make(map[string]struct{})
+	seenNames := make(map[string]struct{})
+
+	for i, rawEntry := range raw.Entries {
+		entry, err := parseJSONEntry(rawEntry)
+		if err == nil {
+			_, colorSeen := seenColors[entry.Color]
+			_, nameSeen := seenNames[entry.Name]
+			bare := entry.Role == "" && (entry.Name == "" || nameSeen)
+			// a dropped duplicate claims no role
+			if colorSeen && bare && !opts.KeepDuplicates {
+				continue
+			}
+			if _, exists := seenRoles[entry.Role]; exists && entry.Role != "" {
+				err = fmt.Errorf("duplicate role name '%v'", entry.Role)
+			}
+		}
+		if err != nil {
+			parseErrs.add(&ParseError{Path: palettePath, Entry: i + 1, Reason: err.Error()})
+			continue
+		}
+		if maxColors > 0 && len(doc.Entries) >= maxColors {
+			parseErrs.Errors = append([]*ParseError{{Path: palettePath,
+				Reason: fmt.Sprintf("Max amount of colors in palette is %v", maxColors)}}, parseErrs.Errors...)
+			break
+		}
+		doc.Entries = append(doc.Entries, entry)
+		seenColors[entry.Color] = struct{}{}
+		if entry.Role != "" {
+			seenRoles[entry.Role] = struct{}{}
+		}
+		if entry.Name != "" {
+			seenNames[entry.Name] = struct{}{}
+		}
+	}
+
+	if len(doc.Entries) < MinColors {
+		parseErrs.Errors = append([]*ParseError{{Path: palettePath,
+			Reason: fmt.Sprintf("Minimum amount of colors in palette is %v", MinColors)}}, parseErrs.Errors...)
+	}
+
+	if len(parseErrs.Errors) > 0 {
+		return nil, parseErrs
+	}
+
+	return doc, nil
+}
+
+// parseJSONEntry validates one entry of a JSON palette, whether its role is
+// taken by another entry is checked by the caller
+func parseJSONEntry(rawEntry jsonEntry) (Entry, error) {
+	c, err := parseHexColor(rawEntry.Hex)
+	if err != nil {
+		return Entry{}, err
+	}
+	if rawEntry.Role != "" {
+		if err := ValidateRole(rawEntry.Role); err != nil {
+			return Entry{}, err
+		}
+	}
+	if rawEntry.Weight < 0 || rawEntry.Weight > 1 {
+		return Entry{}, fmt.Errorf("weight %v is not between 0 and 1", rawEntry.Weight)
+	}
+
+	return Entry{
+		Color:  c.(color.RGBA),
+		Name:   rawEntry.Name,
+		Role:   rawEntry.Role,
+		Weight: rawEntry.Weight,
+	}, nil
+}
+
+// writeJSON writes the document as a JSON palette with all of its metadata
+func writeJSON(w io.Writer, doc *Document, _ SaveOptions) error {
+	raw := jsonDocument{
+		Name:    doc.Name,
+		Author:  doc.Author,
+		Source:  doc.Source,
+		Entries: make([]jsonEntry, 0, len(doc.Entries)),
+	}
+	for _, e := range doc.Entries {
+		raw.Entries = append(raw.Entries, jsonEntry{
+			Hex:    FormatHexColor(e.Color),
+			Name:   e.Name,
+			Role:   e.Role,
+			Weight: math.Round(e.Weight*1e6) / 1e6,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(raw)
+}
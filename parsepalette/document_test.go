@@ -0,0 +1,146 @@
+package parsepalette
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func Test_SaveDocumentJSON(t *testing.T) {
+	doc := &Document{
+		Name:   "Latte",
+		Author: "Catppuccin",
+		Source: "original-image.jpg",
+		Entries: []Entry{
+			{Color: color.RGBA{0xef, 0xf1, 0xf5, 0xff}, Name: "Base", Role: "background", Weight: 0.42},
+			{Color: color.RGBA{0x4c, 0x4f, 0x69, 0xff}, Name: "Text", Role: "foreground", Weight: 0.08},
+			{Color: color.RGBA{0xea, 0x76, 0xcb, 0xff}},
+		},
+	}
+	outputPath := filepath.Join(t.TempDir(), "palette.json")
+
+	if err := SaveDocument(outputPath, doc, SaveOptions{}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	readDoc, err := ParseDocument(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if !reflect.DeepEqual(doc, readDoc) {
+		t.Errorf("Expected document %+v, got %+v", doc, readDoc)
+	}
+
+	palette, err := ParsePalette(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(palette) != len(doc.Entries) || palette[0] != doc.Entries[0].Color {
+		t.Errorf("Expected palette of the document's colors, got %v", palette)
+	}
+}
+
+func Test_ParseDocumentJSONErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		entry   int // entry of one of the errors, 0 for the palette as a whole
+	}{
+		{"invalid hex", `{"entries": [{"hex": "#fff"}, {"hex": "fff"}, {"hex": "#000"}]}`, 2},
+		{"invalid role", `{"entries": [{"hex": "#fff", "role": "bg color"}, {"hex": "#000"}]}`, 1},
+		{"duplicate role", `{"entries": [{"hex": "#fff", "role": "bg"}, {"hex": "#000", "role": "bg"}]}`, 2},
+		{"invalid weight", `{"entries": [{"hex": "#fff", "weight": 1.5}, {"hex": "#000"}]}`, 1},
+		{"too few colors", `{"entries": [{"hex": "#fff"}, {"hex": "#FFFFFF"}]}`, 0},
+		{"invalid json", `{"entries": [`, 0},
+	}
+
+	for _, tt := range tests {
+		inputPath := filepath.Join(t.TempDir(), "palette.json")
+		if err := os.WriteFile(inputPath, []byte(tt.content), 0o644); err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+		_, err := ParseDocument(inputPath)
+		var parseErrs *ParseErrors
+		if !errors.As(err, &parseErrs) {
+			t.Errorf("Expected ParseErrors for %v, got: %v", tt.name, err)
+			continue
+		}
+		if !slices.ContainsFunc(parseErrs.Errors, func(e *ParseError) bool {
+			return e.Path == inputPath && e.Entry == tt.entry
+		}) {
+			t.Errorf("Expected an error for %v on entry %v, got: %v", tt.name, tt.entry, err)
+		}
+	}
+}
+
+func Test_ParseDocumentIncludedJSONErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"base.json":   `{"entries": [{"hex": "#fff"}, {"hex": "white"}, {"hex": "#000"}]}`,
+		"palette.txt": "#123456 -> #abcdef : 1\n#111111\n#222222\n@include base.json\n",
+	})
+
+	_, err := ParseDocument(filepath.Join(dir, "palette.txt"))
+	var parseErrs *ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("Expected ParseErrors, got: %v", err)
+	}
+	if len(parseErrs.Errors) != 2 {
+		t.Fatalf("Expected the ramp and the included entry errors, got: %v", err)
+	}
+	if got := parseErrs.Errors[0]; got.Line != 1 {
+		t.Errorf("Expected the ramp error on line 1, got: %v", got)
+	}
+	expectedMessage := filepath.Join(dir, "base.json") + ": entry 2: invalid hex color 'white'"
+	if got := parseErrs.Errors[1].Error(); got != expectedMessage {
+		t.Errorf("Expected %q, got %q", expectedMessage, got)
+	}
+}
+
+func Test_ParseDocumentJSONSharedColors(t *testing.T) {
+	// roles and names sharing a color are kept, a bare repeated color or a
+	// repeated name is dropped
+	content := `{"entries": [
+		{"hex": "#fff", "role": "background"},
+		{"hex": "#FFFFFF", "role": "surface"},
+		{"hex": "#000", "name": "Ink"},
+		{"hex": "#000000", "name": "Night"},
+		{"hex": "#000", "name": "Ink"},
+		{"hex": "#fff"}
+	]}`
+	inputPath := filepath.Join(t.TempDir(), "palette.json")
+	if err := os.WriteFile(inputPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	doc, err := ParseDocument(inputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	var got []string
+	for _, e := range doc.Entries {
+		got = append(got, e.Role+e.Name)
+	}
+	expected := []string{"background", "surface", "Ink", "Night"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected entries %v, got %v", expected, got)
+	}
+}
+
+func Test_ParseDocumentText(t *testing.T) {
+	doc, err := ParseDocument(testPaletteInputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(doc.Entries) != 28 {
+		t.Fatalf("Expected 28 entries, got %v", len(doc.Entries))
+	}
+	expected := Entry{Color: color.RGBA{51, 51, 51, 255}}
+	if doc.Entries[0] != expected {
+		t.Errorf("Expected first entry %+v, got %+v", expected, doc.Entries[0])
+	}
+}
@@ -0,0 +1,116 @@
+package parsepalette
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError is a problem found at a position in a palette file. Line and
+// Column are 1-based and refer to the file as written, Entry is the 1-based
+// entry of a JSON palette, they are 0 for problems with the palette as a
+// whole.
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Entry  int
+	Token  string // offending text, if any
+	Reason string
+	Err    error // underlying error, if any, printed after Reason
+}
+
+// Error formats the error like a compiler diagnostic, 'file:line:col: reason',
+// or 'file: entry n: reason' for JSON palettes.
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Path)
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, ":%d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&sb, ":%d", e.Column)
+		}
+	}
+	if e.Entry > 0 {
+		fmt.Fprintf(&sb, ": entry %d", e.Entry)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(e.Reason)
+	if e.Err != nil {
+		if e.Reason != "" {
+			sb.WriteString(": ")
+		}
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors is the list of errors found while parsing a palette file, at
+// most maxParseErrors of them with Omitted counting the rest.
+type ParseErrors struct {
+	Errors  []*ParseError
+	Omitted int
+}
+
+// Error lists the errors one per line.
+func (e *ParseErrors) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	for _, err := range e.Errors {
+		lines = append(lines, err.Error())
+	}
+	if e.Omitted > 0 {
+		lines = append(lines, fmt.Sprintf("%v more errors...", e.Omitted))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the errors so that errors.As finds the first ParseError.
+func (e *ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// add records an error, counting it in Omitted once maxParseErrors errors
+// have been recorded
+func (e *ParseErrors) add(err *ParseError) {
+	if len(e.Errors) < maxParseErrors {
+		e.Errors = append(e.Errors, err)
+	} else {
+		e.Omitted++
+	}
+}
+
+// sourceLine is a non-empty line of a palette file with its position in the
+// file and its parsed node, Column is where the trimmed Text starts
+type sourceLine struct {
+	Path   string
+	Number int
+	Column int
+	Text   string
+	node   Node
+}
+
+// errorAt builds a ParseError for a token on the line, offset being the
+// token's byte offset in the line's Text
+func (l sourceLine) errorAt(offset int, token string, reason string) *ParseError {
+	return &ParseError{
+		Path:   l.Path,
+		Line:   l.Number,
+		Column: l.Column + offset,
+		Token:  token,
+		Reason: reason,
+	}
+}
+
+// withError returns the line with its node replaced by the error, used for
+// lines that fail after parsing such as includes
+func (l sourceLine) withError(err *ParseError) sourceLine {
+	l.node.err = err
+	return l
+}
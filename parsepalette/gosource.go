@@ -0,0 +1,102 @@
+package parsepalette
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// DefaultGoPackage is the package name of Go source output when none is given.
+const DefaultGoPackage = "palette"
+
+// writeGoSource writes the document as a gofmt formatted Go file declaring a
+// color.Palette variable named after the document
+func writeGoSource(w io.Writer, doc *Document, opts SaveOptions) error {
+	packageName := opts.GoPackage
+	if packageName == "" {
+		packageName = DefaultGoPackage
+	}
+	if err := ValidateGoPackage(packageName); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by csor. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&sb, "package %v\n\n", packageName)
+	sb.WriteString("import \"image/color\"\n\n")
+	if doc.Source != "" {
+		fmt.Fprintf(&sb, "// %v is a palette from %v.\n", goIdentifier(doc.Name), singleLine(doc.Source))
+	}
+	fmt.Fprintf(&sb, "var %v = color.Palette{\n", goIdentifier(doc.Name))
+	for _, e := range doc.Entries {
+		fmt.Fprintf(&sb, "color.RGBA{R: 0x%02x, G: 0x%02x, B: 0x%02x, A: 0x%02x},", e.Color.R, e.Color.G, e.Color.B, e.Color.A)
+		if comment := entryComment(e); comment != "" {
+			fmt.Fprintf(&sb, " // %v", comment)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("}\n")
+
+	source, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return fmt.Errorf("failed to format Go source: %w", err)
+	}
+
+	_, err = w.Write(source)
+	return err
+}
+
+// ValidateGoPackage checks that a name can be used as a Go package name.
+func ValidateGoPackage(packageName string) error {
+	if !token.IsIdentifier(packageName) || packageName == "_" {
+		return fmt.Errorf("invalid Go package name '%v'", truncateString(packageName, 30))
+	}
+	return nil
+}
+
+// goIdentifier turns a palette name into an exported Go identifier by
+// capitalizing its words and dropping anything else, 'Palette' if none is left
+func goIdentifier(name string) string {
+	var sb strings.Builder
+	upperNext := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			sb.WriteString("Palette")
+		}
+		if upperNext {
+			r = unicode.ToUpper(r)
+			upperNext = false
+		}
+		sb.WriteRune(r)
+	}
+
+	identifier := sb.String()
+	if identifier == "" || !token.IsExported(identifier) {
+		return "Palette" + identifier
+	}
+	return identifier
+}
+
+// entryComment describes the name and role of an entry, if it has any
+func entryComment(e Entry) string {
+	switch name := singleLine(e.Name); {
+	case name != "" && e.Role != "":
+		return fmt.Sprintf("%v (%v)", name, e.Role)
+	case name != "":
+		return name
+	default:
+		return e.Role
+	}
+}
+
+// singleLine collapses all whitespace, including newlines, to single spaces
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,95 @@
+package parsepalette
+
+import (
+	"go/parser"
+	"go/token"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_SaveDocumentGoSource(t *testing.T) {
+	doc := &Document{
+		Name:   "catppuccin latte",
+		Source: "original-image.jpg",
+		Entries: []Entry{
+			{Color: color.RGBA{0xef, 0xf1, 0xf5, 0xff}, Name: "Base", Role: "background"},
+			{Color: color.RGBA{0x4c, 0x4f, 0x69, 0xff}, Role: "foreground"},
+			{Color: color.RGBA{0xea, 0x76, 0xcb, 0xff}},
+		},
+	}
+	outputPath := filepath.Join(t.TempDir(), "latte.go")
+
+	if err := SaveDocument(outputPath, doc, SaveOptions{GoPackage: "themes"}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	expected := `// Code generated by csor. DO NOT EDIT.
+
+package themes
+
+import "image/color"
+
+// CatppuccinLatte is a palette from original-image.jpg.
+var CatppuccinLatte = color.Palette{
+	color.RGBA{R: 0xef, G: 0xf1, B: 0xf5, A: 0xff}, // Base (background)
+	color.RGBA{R: 0x4c, G: 0x4f, B: 0x69, A: 0xff}, // foreground
+	color.RGBA{R: 0xea, G: 0x76, B: 0xcb, A: 0xff},
+}
+`
+	if string(content) != expected {
+		t.Errorf("Expected Go source:\n%v\ngot:\n%s", expected, content)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), outputPath, content, 0); err != nil {
+		t.Errorf("Expected valid Go source, got error: %v", err)
+	}
+}
+
+func Test_SaveDocumentGoSourceInvalidPackage(t *testing.T) {
+	doc := &Document{Entries: []Entry{{Color: color.RGBA{0, 0, 0, 0xff}}}}
+	outputPath := filepath.Join(t.TempDir(), "palette.go")
+	existing := []byte("package themes\n")
+	if err := os.WriteFile(outputPath, existing, 0o644); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	if err := SaveDocument(outputPath, doc, SaveOptions{GoPackage: "my-palette"}); err == nil {
+		t.Errorf("Expected error for invalid package name, got none")
+	}
+
+	// a failing writer must leave the existing file untouched
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if string(content) != string(existing) {
+		t.Errorf("Expected existing file to be kept, got %q", content)
+	}
+}
+
+func Test_GoIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", "Palette"},
+		{"nord", "Nord"},
+		{"catppuccin latte", "CatppuccinLatte"},
+		{"tokyo-night storm", "TokyoNightStorm"},
+		{"8bit", "Palette8bit"},
+		{"pico 8", "Pico8"},
+		{"!!!", "Palette"},
+	}
+
+	for _, tt := range tests {
+		if got := goIdentifier(tt.name); got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.name, got)
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package parsepalette
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"image/color"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -12,15 +15,61 @@ import (
 )
 
 const (
-	MinColors            int = 2
-	MaxColors            int = 128
+	MinColors int = 2
+	// DefaultMaxColors is the most colors a palette can have unless the
+	// ParseOptions change it, as many as an image.Paletted can hold.
+	DefaultMaxColors     int = 256
+	maxRampSteps             = 4096
 	maxParseErrors           = 15
 	maxPaletteFileSizeMB     = 1
+	maxIncludeDepth          = 16
+
+	includeDirective = "@include"
+	excludeDirective = "@exclude"
 )
 
+// ParseOptions are the settings of ParsePaletteWithOptions.
+type ParseOptions struct {
+	// MaxColors is the most colors the palette can have, 0 for no limit
+	MaxColors int
+	// KeepDuplicates keeps colors repeating an earlier color instead of
+	// leaving them out, for tools that report them
+	KeepDuplicates bool
+}
+
 // ParsePalette reads a palette file from the given path, validates its size,
-// and parses the colors, returning a color.Palette.
+// and parses the colors, returning a color.Palette of at most DefaultMaxColors.
 func ParsePalette(paletteInputPath string) (color.Palette, error) {
+	return ParsePaletteWithOptions(paletteInputPath, ParseOptions{MaxColors: DefaultMaxColors})
+}
+
+// ParsePaletteWithOptions reads a palette file like ParsePalette, with the
+// color limit given by the options.
+func ParsePaletteWithOptions(paletteInputPath string, opts ParseOptions) (color.Palette, error) {
+	doc, err := ParseDocumentWithOptions(paletteInputPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Palette(), nil
+}
+
+// ParseDocument reads a palette file from the given path together with its
+// metadata. '.json' files are read as JSON palettes, any other file as a text
+// palette of hex colors whose entries only have a color. A path starting with
+// BuiltinPrefix names a built-in palette instead.
+func ParseDocument(paletteInputPath string) (*Document, error) {
+	return ParseDocumentWithOptions(paletteInputPath, ParseOptions{MaxColors: DefaultMaxColors})
+}
+
+// ParseDocumentWithOptions reads a palette file like ParseDocument, with the
+// color limit and duplicate handling given by the options, which also apply
+// to built-in and included palettes.
+func ParseDocumentWithOptions(paletteInputPath string, opts ParseOptions) (*Document, error) {
+	if isBuiltinPath(paletteInputPath) {
+		return builtinDocument(paletteInputPath, opts)
+	}
+
 	file, err := os.Open(paletteInputPath)
 	if err != nil {
 		return nil, fmt.Errorf("could not open file: %w", err)
@@ -31,84 +80,249 @@ func ParsePalette(paletteInputPath string) (color.Palette, error) {
 		return nil, err
 	}
 
-	lines, err := readNonEmptyLines(file)
+	if strings.ToLower(filepath.Ext(paletteInputPath)) == ".json" {
+		return parseJSONDocument(paletteInputPath, file, opts)
+	}
+
+	lines, err := readSourceLines(file)
 	if err != nil {
 		return nil, err
 	}
 
-	colors, err := parseColorsFromLines(lines)
+	lines, err = expandIncludes(paletteInputPath, lines, nil, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	return colors, nil
+	colors, err := parseColorsFromLines(paletteInputPath, lines, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return documentFromColors(colors), nil
 }
 
-// readNonEmptyLines reads all non-empty lines from a file,
-// ignoring comments, and returns them as a slice of strings.
-func readNonEmptyLines(file *os.File) ([]string, error) {
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(strings.Split(scanner.Text(), "//")[0])
-		if line != "" {
-			lines = append(lines, line)
+// expandIncludes replaces every '@include <path>' line with the lines of the
+// included palette file. Relative paths are resolved from the directory of the
+// including file, includeStack holds the files currently being included to
+// detect cycles. An include that fails is replaced by lines holding its
+// errors, so that they are reported with the rest of the file's errors.
+// Included palettes keep duplicates as the options say, the color limit is
+// checked on the whole palette by parseColorsFromLines.
+func expandIncludes(palettePath string, lines []sourceLine, includeStack []string, opts ParseOptions) ([]sourceLine, error) {
+	includeOpts := ParseOptions{KeepDuplicates: opts.KeepDuplicates}
+	absPath, err := filepath.Abs(palettePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve palette path: %w", err)
+	}
+	includeStack = append(includeStack, absPath)
+
+	expanded := make([]sourceLine, 0, len(lines))
+	for _, line := range lines {
+		if line.node.Kind != IncludeNode {
+			expanded = append(expanded, line)
+			continue
+		}
+		includePath, _ := directiveArgument(line.Text, includeDirective)
+
+		argument := includePath
+		includeErr := func(reason string, err error) {
+			parseErr := line.errorAt(argumentOffset(line.Text, argument), argument, reason)
+			parseErr.Err = err
+			expanded = append(expanded, line.withError(parseErr))
 		}
+
+		if includePath == "" {
+			includeErr(fmt.Sprintf("missing path after %v", includeDirective), nil)
+			continue
+		}
+		if isBuiltinPath(includePath) {
+			doc, err := builtinDocument(includePath, includeOpts)
+			if err != nil {
+				includeErr("", err)
+				continue
+			}
+			for _, e := range doc.Entries {
+				expanded = append(expanded, colorLine(includePath, e.Color))
+			}
+			continue
+		}
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(palettePath), includePath)
+		}
+		absInclude, err := filepath.Abs(includePath)
+		if err != nil {
+			includeErr("could not resolve included path", err)
+			continue
+		}
+		if slices.Contains(includeStack, absInclude) {
+			includeErr(fmt.Sprintf("include cycle: %v -> %v",
+				strings.Join(includeStack, " -> "), absInclude), nil)
+			continue
+		}
+		if len(includeStack) >= maxIncludeDepth {
+			includeErr(fmt.Sprintf("includes are nested deeper than %v files", maxIncludeDepth), nil)
+			continue
+		}
+
+		includedLines, err := readIncludedLines(includePath, includeStack, includeOpts)
+		if err != nil {
+			var parseErrs *ParseErrors
+			if !errors.As(err, &parseErrs) {
+				includeErr("", err)
+				continue
+			}
+			for _, parseErr := range parseErrs.Errors {
+				expanded = append(expanded, line.withError(parseErr))
+			}
+			continue
+		}
+		expanded = append(expanded, includedLines...)
 	}
 
-	if err := scanner.Err(); err != nil {
+	return expanded, nil
+}
+
+// readIncludedLines reads the lines of an included palette file, expanding
+// its own includes. An included JSON palette contributes its colors.
+func readIncludedLines(includePath string, includeStack []string, opts ParseOptions) ([]sourceLine, error) {
+	file, err := os.Open(includePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open included file: %w", err)
+	}
+	defer file.Close()
+
+	if err := utility.ValidateFileSize(file, "Included palette", maxPaletteFileSizeMB); err != nil {
+		return nil, err
+	}
+
+	if strings.ToLower(filepath.Ext(includePath)) == ".json" {
+		doc, err := parseJSONDocument(includePath, file, opts)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]sourceLine, 0, len(doc.Entries))
+		for _, e := range doc.Entries {
+			lines = append(lines, colorLine(includePath, e.Color))
+		}
+		return lines, nil
+	}
+
+	lines, err := readSourceLines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return expandIncludes(includePath, lines, includeStack, opts)
+}
+
+// directiveArgument reports whether the line is the given directive,
+// returning the rest of the line as its argument
+func directiveArgument(line, directive string) (string, bool) {
+	rest, found := strings.CutPrefix(line, directive)
+	if !found || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// argumentOffset returns the byte offset of a directive's argument in the
+// line, or the end of the line for a missing argument
+func argumentOffset(line, argument string) int {
+	if argument == "" {
+		return len(line)
+	}
+	return max(0, strings.LastIndex(line, argument))
+}
+
+// readSourceLines parses a text palette file with parseNode and returns its
+// lines holding a color, ramp or directive with their position in the file.
+func readSourceLines(file *os.File) ([]sourceLine, error) {
+	src, err := io.ReadAll(file)
+	if err != nil {
 		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
-	return lines, nil
+	return parseFile(file.Name(), src).codeLines(), nil
 }
 
-// parseColorsFromLines parses the hex colors from the lines to RGBA,
-// returning a slice of colors
-func parseColorsFromLines(lines []string) ([]color.Color, error) {
-	colors := make([]color.Color, 0, MaxColors)
-	errors := make([]string, 0, maxParseErrors)
-	errCount := 0
+// parseColorsFromLines parses the hex colors and ramps from the lines to
+// RGBA, returning a slice of colors. Colors named by an '@exclude <hex>' line
+// are left out wherever they appear, as are colors repeating an earlier color
+// unless the options keep duplicates. More than the options' MaxColors colors
+// is an error unless it is 0. Problems are returned as ParseErrors, palette
+// wide ones reported against palettePath.
+func parseColorsFromLines(palettePath string, lines []sourceLine, opts ParseOptions) ([]color.Color, error) {
+	maxColors := opts.MaxColors
+	var colors []color.Color
+	parseErrs := &ParseErrors{}
 	seenColors := make(map[color.Color]struct{})
+	excludedColors := make(map[color.Color]struct{})
 
-	for ln, line := range lines {
-		rgba, err := parseHexColor(line)
-		if err != nil {
-			if errCount < maxParseErrors {
-				errors = append(errors, fmt.Sprintf("Error on line %v: %v", ln+1, err))
-			}
-			errCount++
-			if errCount >= maxParseErrors {
-				break
+	for _, line := range lines {
+		if line.node.Kind == ExcludeNode && line.node.err == nil {
+			excludedColors[line.node.Color] = struct{}{}
+		}
+	}
+
+lines:
+	for _, line := range lines {
+		var lineColors []color.Color
+		lineErr := line.node.err
+		if lineErr == nil {
+			switch line.node.Kind {
+			case ExcludeNode:
+				// exclusions were collected above
+				continue
+			case RampNode:
+				lineColors, lineErr = parseRamp(line, maxColors)
+			case ColorNode:
+				lineColors = []color.Color{line.node.Color}
 			}
-			continue
 		}
-		if len(colors) >= MaxColors {
-			errors = append([]string{fmt.Sprintf("Max amount of colors in palette is %v", MaxColors)}, errors...)
-			break
+		if lineErr != nil {
+			parseErrs.add(lineErr)
+			continue
 		}
-		if _, exists := seenColors[rgba]; !exists {
+		for _, rgba := range lineColors {
+			if _, excluded := excludedColors[rgba]; excluded {
+				continue
+			}
+			if _, exists := seenColors[rgba]; exists && !opts.KeepDuplicates {
+				continue
+			}
+			if maxColors > 0 && len(colors) >= maxColors {
+				parseErrs.Errors = append([]*ParseError{{Path: palettePath,
+					Reason: fmt.Sprintf("Max amount of colors in palette is %v", maxColors)}}, parseErrs.Errors...)
+				break lines
+			}
 			colors = append(colors, rgba)
 			seenColors[rgba] = struct{}{}
 		}
 	}
 
 	if len(colors) < MinColors {
-		errors = append([]string{fmt.Sprintf("Minimum amount of colors in palette is %v", MinColors)}, errors...)
-		errCount++
+		parseErrs.Errors = append([]*ParseError{{Path: palettePath,
+			Reason: fmt.Sprintf("Minimum amount of colors in palette is %v", MinColors)}}, parseErrs.Errors...)
 	}
 
-	if errCount > 0 {
-		allErrors := strings.Join(errors, "\n")
-		if errCount > len(errors) {
-			allErrors = fmt.Sprintf("%v\n%v more errors...", allErrors, errCount-len(errors))
-		}
-		return colors, fmt.Errorf(allErrors)
+	if len(parseErrs.Errors) > 0 {
+		return colors, parseErrs
 	}
 
 	return colors, nil
 }
 
+// ParseHexColor parses a '#RGB' or '#RRGGBB' hex color string, as given on
+// the command line.
+func ParseHexColor(hexColorString string) (color.RGBA, error) {
+	c, err := parseHexColor(hexColorString)
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return c.(color.RGBA), nil
+}
+
 // parseHexColor parses the RGBA color from a hex color string then returns it
 func parseHexColor(hexColorString string) (color.Color, error) {
 	hexColorString = strings.TrimSpace(hexColorString)
@@ -160,29 +374,19 @@ func parseHexPair(hexPair string) (byte, error) {
 	return byte(value), nil
 }
 
-// SaveNewPalette saves a Palette as a plain text file of hex colors,
-// one color per line
+// SaveNewPalette saves a Palette in the format selected by the output file
+// extension, by default as a plain text file of hex colors, one color per line
 func SaveNewPalette(paletteOutputPath string, palette color.Palette) error {
-	hexColors := make([]string, 0, len(palette))
+	entries := make([]Entry, 0, len(palette))
 	for _, c := range palette {
-		r, g, b, _ := c.RGBA()
-		color := fmt.Sprintf("#%02X%02X%02X", byte(r>>8), byte(g>>8), byte(b>>8))
-		hexColors = append(hexColors, color)
-	}
-
-	outputFile, err := os.Create(paletteOutputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create palette file: %w", err)
+		entries = append(entries, Entry{Color: color.RGBAModel.Convert(c).(color.RGBA)})
 	}
-	defer outputFile.Close()
-
-	writer := bufio.NewWriter(outputFile)
-	defer writer.Flush()
 
-	_, err = writer.WriteString(strings.Join(hexColors, "\n"))
-	if err != nil {
-		return fmt.Errorf("failed to write palette to file: %w", err)
-	}
+	return SaveDocument(paletteOutputPath, &Document{Entries: entries}, SaveOptions{})
+}
 
-	return nil
+// FormatHexColor formats a color as an uppercase #RRGGBB string.
+func FormatHexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02X%02X%02X", byte(r>>8), byte(g>>8), byte(b>>8))
 }
@@ -1,7 +1,11 @@
 package parsepalette
 
 import (
+	"errors"
 	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -144,3 +148,328 @@ func Test_SaveNewPalette(t *testing.T) {
 		}
 	}
 }
+
+func Test_SaveDocumentAnnotated(t *testing.T) {
+	entries := []Entry{
+		{Color: color.RGBA{0xea, 0x76, 0xcb, 0xff}, Weight: 0.234},
+		{Color: color.RGBA{0x1e, 0x66, 0xf5, 0xff}, Weight: 0.5},
+		{Color: color.RGBA{0x00, 0x00, 0x00, 0xff}, Weight: 0.266},
+	}
+	outputPath := filepath.Join(t.TempDir(), "annotated.txt")
+
+	if err := SaveDocument(outputPath, &Document{Entries: entries}, SaveOptions{}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	firstLine := strings.Split(string(content), "\n")[0]
+	expectedLine := "#EA76CB //  23.4% rgb(234, 118, 203) hsl(316, 73%, 69%)"
+	if firstLine != expectedLine {
+		t.Errorf("Expected line %q, got %q", expectedLine, firstLine)
+	}
+
+	readPalette, err := ParsePalette(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(readPalette) != len(entries) {
+		t.Fatalf("Expected same palette len, got: write=%v, read=%v", len(entries), len(readPalette))
+	}
+	for i, e := range entries {
+		if readPalette[i] != e.Color {
+			t.Errorf("expected: %v, got: %v", e.Color, readPalette[i])
+		}
+	}
+}
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+	}
+}
+
+func Test_ParsePaletteIncludeExclude(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"base/base.txt":    "#111111\n#222222 // shared\n#333333\n",
+		"base/neutral.txt": "@include base.txt\n#444444\n",
+		"project/app.txt": "// accents on top of the base palette\n" +
+			"@include ../base/neutral.txt\n" +
+			"#ea76cb\n" +
+			"@exclude #222222\n" +
+			"@exclude #abc\n",
+	})
+
+	palette, err := ParsePalette(filepath.Join(dir, "project", "app.txt"))
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	expected := color.Palette{
+		color.RGBA{0x11, 0x11, 0x11, 0xff},
+		color.RGBA{0x33, 0x33, 0x33, 0xff},
+		color.RGBA{0x44, 0x44, 0x44, 0xff},
+		color.RGBA{0xea, 0x76, 0xcb, 0xff},
+	}
+	if len(expected) != len(palette) {
+		t.Fatalf("Expected palette %v, got %v", expected, palette)
+	}
+	for i := range expected {
+		if expected[i] != palette[i] {
+			t.Errorf("Expected color %v, got %v", expected[i], palette[i])
+		}
+	}
+}
+
+func Test_ParsePaletteIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"a.txt":         "#111111\n@include b.txt\n",
+		"b.txt":         "#222222\n@include a.txt\n",
+		"self.txt":      "#111111\n#222222\n@include self.txt\n",
+		"missing.txt":   "#111111\n#222222\n@include nowhere.txt\n",
+		"empty.txt":     "#111111\n#222222\n@include\n",
+		"unknown.txt":   "#111111\n#222222\n@import other.txt\n",
+		"bad-excl.txt":  "#111111\n#222222\n@exclude red\n",
+		"too-few.txt":   "#111111\n#222222\n@exclude #111\n@exclude #222\n",
+		"includes1.txt": "@includes a.txt\n#111111\n#222222\n",
+	})
+
+	tests := []struct {
+		file     string
+		contains string
+	}{
+		{"a.txt", "include cycle"},
+		{"self.txt", "include cycle"},
+		{"missing.txt", "could not open included file"},
+		{"empty.txt", "missing path"},
+		{"unknown.txt", "unknown directive '@import'"},
+		{"bad-excl.txt", "invalid hex color 'red'"},
+		{"too-few.txt", "Minimum amount of colors"},
+		{"includes1.txt", "unknown directive '@includes'"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParsePalette(filepath.Join(dir, tt.file))
+		if err == nil {
+			t.Errorf("Expected error for %v, got none", tt.file)
+		} else if !strings.Contains(err.Error(), tt.contains) {
+			t.Errorf("Expected error for %v to contain %q, got: %v", tt.file, tt.contains, err)
+		}
+	}
+}
+
+func Test_ParsePaletteRamp(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"ramp.txt": "#ea76cb\n" +
+			"#000000 -> #ffffff : 3 srgb // grays\n" +
+			"#1e1e2e -> #cdd6f4 : 8 oklch\n" +
+			"#ff0000->#0000ff:2\n",
+		"bad-steps.txt": "#000000 -> #ffffff : 1\n",
+		"bad-space.txt": "#000000 -> #ffffff : 4 cmyk\n",
+		"bad-end.txt":   "#000000 -> white : 4\n",
+		"no-steps.txt":  "#000000 -> #ffffff\n",
+	})
+
+	palette, err := ParsePalette(filepath.Join(dir, "ramp.txt"))
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(palette) != 1+3+8+2 {
+		t.Fatalf("Expected 14 colors, got %v: %v", len(palette), palette)
+	}
+
+	expected := map[int]color.RGBA{
+		0:  {0xea, 0x76, 0xcb, 0xff},
+		1:  {0x00, 0x00, 0x00, 0xff},
+		2:  {0x80, 0x80, 0x80, 0xff},
+		3:  {0xff, 0xff, 0xff, 0xff},
+		4:  {0x1e, 0x1e, 0x2e, 0xff},
+		11: {0xcd, 0xd6, 0xf4, 0xff},
+		12: {0xff, 0x00, 0x00, 0xff},
+		13: {0x00, 0x00, 0xff, 0xff},
+	}
+	for i, c := range expected {
+		if palette[i] != c {
+			t.Errorf("Expected color %v at index %v, got %v", c, i, palette[i])
+		}
+	}
+
+	tests := []struct {
+		file     string
+		contains string
+	}{
+		{"bad-steps.txt", "invalid ramp steps '1'"},
+		{"bad-space.txt", "invalid color space 'cmyk'"},
+		{"bad-end.txt", "invalid hex color 'white'"},
+		{"no-steps.txt", "missing ': <steps>'"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParsePalette(filepath.Join(dir, tt.file))
+		if err == nil {
+			t.Errorf("Expected error for %v, got none", tt.file)
+		} else if !strings.Contains(err.Error(), tt.contains) {
+			t.Errorf("Expected error for %v to contain %q, got: %v", tt.file, tt.contains, err)
+		}
+	}
+}
+
+func Test_ParsePaletteErrorPositions(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"errors.txt": "// header\n" +
+			"#111111\n" +
+			"\n" +
+			"  #12345g   // bad\n" +
+			"#222222 -> #333333 : 4 cmyk\n" +
+			"@exclude red\n" +
+			"#444444\n",
+		"include.txt": "#111111\n#222222\n\n@include nowhere.txt\n",
+		"mixed.txt":   "#111111\n#222222\n#333333 -> #444444 : 1\n#555555\n\n// more\n@include\n",
+	})
+
+	path := filepath.Join(dir, "errors.txt")
+	_, err := ParsePalette(path)
+
+	var parseErrs *ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("Expected ParseErrors, got: %v", err)
+	}
+
+	expected := []ParseError{
+		{Path: path, Line: 4, Column: 3, Token: "#12345g"},
+		{Path: path, Line: 5, Column: 24, Token: "cmyk"},
+		{Path: path, Line: 6, Column: 10, Token: "red"},
+	}
+	if len(parseErrs.Errors) != len(expected) {
+		t.Fatalf("Expected %v errors, got: %v", len(expected), err)
+	}
+	for i, e := range expected {
+		got := parseErrs.Errors[i]
+		if got.Path != e.Path || got.Line != e.Line || got.Column != e.Column || got.Token != e.Token {
+			t.Errorf("Expected error at %v:%v:%v on %q, got %v:%v:%v on %q",
+				e.Path, e.Line, e.Column, e.Token, got.Path, got.Line, got.Column, got.Token)
+		}
+	}
+
+	expectedMessage := path + ":5:24: invalid color space 'cmyk'"
+	if !strings.HasPrefix(parseErrs.Errors[1].Error(), expectedMessage) {
+		t.Errorf("Expected message to start with %q, got %q", expectedMessage, parseErrs.Errors[1].Error())
+	}
+
+	var parseErr *ParseError
+	_, err = ParsePalette(filepath.Join(dir, "include.txt"))
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, got: %v", err)
+	}
+	if parseErr.Line != 4 || parseErr.Column != 10 || parseErr.Token != "nowhere.txt" {
+		t.Errorf("Expected include error at 4:10 on 'nowhere.txt', got %v:%v on %q",
+			parseErr.Line, parseErr.Column, parseErr.Token)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected include error to wrap os.ErrNotExist, got: %v", err)
+	}
+
+	_, err = ParsePalette(filepath.Join(dir, "mixed.txt"))
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("Expected ParseErrors, got: %v", err)
+	}
+	if len(parseErrs.Errors) != 2 || parseErrs.Errors[0].Line != 3 || parseErrs.Errors[1].Line != 7 {
+		t.Errorf("Expected the ramp error on line 3 and the include error on line 7, got: %v", err)
+	}
+}
+
+func Test_ParsePaletteMaxColors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "large.txt")
+	writeTestFiles(t, dir, map[string]string{
+		"large.txt": "#000000 -> #ff0000 : 200 srgb\n#00ff00 -> #0000ff : 200 srgb\n",
+	})
+
+	if _, err := ParsePalette(path); err == nil || !strings.Contains(err.Error(), "Max amount of colors in palette is 256") {
+		t.Errorf("Expected max colors error, got: %v", err)
+	}
+
+	palette, err := ParsePaletteWithOptions(path, ParseOptions{MaxColors: 0})
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	if len(palette) != 400 {
+		t.Errorf("Expected 400 colors, got %v", len(palette))
+	}
+
+	if _, err := ParsePaletteWithOptions(path, ParseOptions{MaxColors: 100}); err == nil {
+		t.Errorf("Expected error for ramp longer than the color limit, got none")
+	}
+}
+
+func Test_ParseDocumentKeepDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"palette.txt":  "#000 -> #fff : 3\n#ea76cb\n#FFFFFF\n#EA76CB\n",
+		"palette.json": `{"entries": [{"hex": "#000000"}, {"hex": "#ea76cb"}, {"hex": "#000"}]}`,
+		"include.txt":  "@include palette.json\n#ea76cb\n",
+	})
+
+	tests := []struct {
+		fileName string
+		expected int
+	}{
+		{"palette.txt", 6},
+		{"palette.json", 3},
+		{"include.txt", 4},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.fileName)
+		doc, err := ParseDocumentWithOptions(path, ParseOptions{KeepDuplicates: true})
+		if err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+		if len(doc.Entries) != tt.expected {
+			t.Errorf("Expected %v entries in %v, got %v", tt.expected, tt.fileName, doc.Entries)
+		}
+
+		deduplicated, err := ParseDocument(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+		if len(deduplicated.Entries) >= tt.expected {
+			t.Errorf("Expected duplicates of %v to be left out by default, got %v", tt.fileName, deduplicated.Entries)
+		}
+	}
+}
+
+func Test_ParsePaletteOmittedErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "errors.txt")
+	writeTestFiles(t, dir, map[string]string{
+		"errors.txt": "#111111\n#222222\n" + strings.Repeat("#12345g\n", maxParseErrors+5),
+	})
+
+	_, err := ParsePalette(path)
+
+	var parseErrs *ParseErrors
+	if !errors.As(err, &parseErrs) {
+		t.Fatalf("Expected ParseErrors, got: %v", err)
+	}
+	if len(parseErrs.Errors) != maxParseErrors || parseErrs.Omitted != 5 {
+		t.Errorf("Expected %v errors and 5 omitted, got %v and %v", maxParseErrors, len(parseErrs.Errors),
+			parseErrs.Omitted)
+	}
+	if !strings.HasSuffix(err.Error(), "\n5 more errors...") {
+		t.Errorf("Expected error to end with the omitted count, got: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package parsepalette
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+)
+
+const (
+	rampArrow     = "->"
+	rampSeparator = ":"
+
+	// defaultRampSpace is used when a ramp line does not name a color space
+	defaultRampSpace = colorspace.SpaceOKLab
+)
+
+// isRampLine reports whether the line is a gradient between two colors
+func isRampLine(line string) bool {
+	return strings.Contains(line, rampArrow)
+}
+
+// parseRamp expands a line like '#1e1e2e -> #cdd6f4 : 8 oklch' into the given
+// number of colors interpolated between both ends in the named color space,
+// the ends included. The color space may be left out to use OKLab. A ramp
+// has at most maxRampSteps colors, and at most maxColors unless it is 0.
+func parseRamp(line sourceLine, maxColors int) ([]color.Color, *ParseError) {
+	text := line.Text
+	sepIndex := strings.Index(text, rampSeparator)
+	if sepIndex < 0 {
+		return nil, line.errorAt(len(text), "",
+			fmt.Sprintf("missing '%v <steps>' after ramp '%v'", rampSeparator, truncateString(text, 30)))
+	}
+	arrowIndex := strings.Index(text, rampArrow)
+
+	from := strings.TrimSpace(text[:arrowIndex])
+	fromColor, err := parseHexColor(from)
+	if err != nil {
+		return nil, line.errorAt(tokenOffset(text, 0, from), from, err.Error())
+	}
+	to := strings.TrimSpace(text[arrowIndex+len(rampArrow) : max(sepIndex, arrowIndex+len(rampArrow))])
+	toColor, err := parseHexColor(to)
+	if err != nil {
+		return nil, line.errorAt(tokenOffset(text, arrowIndex+len(rampArrow), to), to, err.Error())
+	}
+
+	specStart := sepIndex + len(rampSeparator)
+	spec := text[specStart:]
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		spec = strings.TrimSpace(spec)
+		return nil, line.errorAt(tokenOffset(text, specStart, spec), spec,
+			fmt.Sprintf("expected '<steps> [space]' after '%v', got '%v'", rampSeparator, truncateString(spec, 30)))
+	}
+
+	stepsOffset := tokenOffset(text, specStart, fields[0])
+	maxSteps := maxRampSteps
+	if maxColors > 0 {
+		maxSteps = min(maxSteps, maxColors)
+	}
+	steps, err := strconv.Atoi(fields[0])
+	if err != nil || steps < 2 || steps > maxSteps {
+		return nil, line.errorAt(stepsOffset, fields[0], fmt.Sprintf("invalid ramp steps '%v' (expected %v to %v)",
+			truncateString(fields[0], 30), 2, maxSteps))
+	}
+
+	space := defaultRampSpace
+	if len(fields) == 2 {
+		if space, err = colorspace.ParseSpace(strings.ToLower(fields[1])); err != nil {
+			return nil, line.errorAt(tokenOffset(text, stepsOffset+len(fields[0]), fields[1]), fields[1], err.Error())
+		}
+	}
+
+	ramp := colorspace.Ramp(fromColor, toColor, steps, space)
+	colors := make([]color.Color, len(ramp))
+	for i, c := range ramp {
+		colors[i] = c
+	}
+	return colors, nil
+}
+
+// tokenOffset returns the byte offset of token in text, searching from start
+func tokenOffset(text string, start int, token string) int {
+	if i := strings.Index(text[start:], token); i >= 0 {
+		return start + i
+	}
+	return start
+}
@@ -0,0 +1,221 @@
+package parsepalette
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+)
+
+// swatch card layout in pixels
+const (
+	cardColumns   = 8
+	cardMargin    = 12
+	cardGap       = 8
+	swatchWidth   = 96
+	swatchHeight  = 72
+	labelPadding  = 6
+	labelScale    = 2
+	coverageGap   = 3
+	coverageBarH  = 6
+	glyphWidth    = 5
+	glyphHeight   = 7
+	glyphAdvance  = glyphWidth + 1
+	darkLuminance = 0.179 // luminance at which black and white text have equal contrast
+)
+
+var (
+	cardBackground  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	cardBorder      = color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+	coverageTrack   = color.RGBA{0xe6, 0xe6, 0xe6, 0xff}
+	coverageFill    = color.RGBA{0x4c, 0x4f, 0x69, 0xff}
+	labelDarkColor  = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	labelLightColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// bitmapFont is a 5x7 font with the glyphs needed for hex color labels,
+// each row is 5 bits wide with the leftmost pixel in the highest bit
+var bitmapFont = map[rune][glyphHeight]uint8{
+	'#': {0b01010, 0b01010, 0b11111, 0b01010, 0b11111, 0b01010, 0b01010},
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'A': {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B': {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C': {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D': {0b11100, 0b10010, 0b10001, 0b10001, 0b10001, 0b10010, 0b11100},
+	'E': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F': {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+}
+
+// swatchCardLayout places the swatches of a card in a grid
+type swatchCardLayout struct {
+	columns, rows int
+	cellHeight    int
+	coverageBars  bool
+	maxWeight     float64
+}
+
+func newSwatchCardLayout(entries []Entry, coverageBars bool) swatchCardLayout {
+	layout := swatchCardLayout{
+		columns:      min(cardColumns, max(1, len(entries))),
+		rows:         max(1, (len(entries)+cardColumns-1)/cardColumns),
+		cellHeight:   swatchHeight,
+		coverageBars: coverageBars,
+	}
+	if coverageBars {
+		layout.cellHeight += coverageGap + coverageBarH
+	}
+	for _, e := range entries {
+		layout.maxWeight = max(layout.maxWeight, e.Weight)
+	}
+	return layout
+}
+
+func (l swatchCardLayout) size() image.Point {
+	return image.Pt(
+		2*cardMargin+l.columns*swatchWidth+(l.columns-1)*cardGap,
+		2*cardMargin+l.rows*l.cellHeight+(l.rows-1)*cardGap,
+	)
+}
+
+// swatch returns the rectangle of the swatch at index i
+func (l swatchCardLayout) swatch(i int) image.Rectangle {
+	x := cardMargin + (i%l.columns)*(swatchWidth+cardGap)
+	y := cardMargin + (i/l.columns)*(l.cellHeight+cardGap)
+	return image.Rect(x, y, x+swatchWidth, y+swatchHeight)
+}
+
+// coverageBar returns the track of the coverage bar below a swatch and the
+// part of it filled by the entry's weight, relative to the heaviest entry
+func (l swatchCardLayout) coverageBar(swatch image.Rectangle, weight float64) (track, fill image.Rectangle) {
+	track = image.Rect(swatch.Min.X, swatch.Max.Y+coverageGap, swatch.Max.X, swatch.Max.Y+coverageGap+coverageBarH)
+	fill = track
+	if l.maxWeight > 0 {
+		fill.Max.X = fill.Min.X + int(float64(track.Dx())*weight/l.maxWeight+0.5)
+	} else {
+		fill.Max.X = fill.Min.X
+	}
+	return track, fill
+}
+
+// labelOrigin returns the top left corner of the hex label inside a swatch
+func labelOrigin(swatch image.Rectangle) image.Point {
+	return image.Pt(swatch.Min.X+labelPadding, swatch.Max.Y-labelPadding-glyphHeight*labelScale)
+}
+
+// labelColor picks black or white text, whichever contrasts more with c
+func labelColor(c color.Color) color.RGBA {
+	if colorspace.RelativeLuminance(c) > darkLuminance {
+		return labelDarkColor
+	}
+	return labelLightColor
+}
+
+// writeSwatchCardPNG writes the entries as a PNG preview card of labeled
+// swatches in a grid. With coverage bars set a bar below every swatch shows its
+// weight relative to the heaviest entry.
+func writeSwatchCardPNG(w io.Writer, doc *Document, opts SaveOptions) error {
+	if err := png.Encode(w, renderSwatchCard(doc.Entries, opts.CoverageBars)); err != nil {
+		return fmt.Errorf("failed to encode swatch card as PNG: %w", err)
+	}
+	return nil
+}
+
+// writeSwatchCardSVG writes the entries as an SVG preview card with the same
+// layout as writeSwatchCardPNG.
+func writeSwatchCardSVG(w io.Writer, doc *Document, opts SaveOptions) error {
+	_, err := io.WriteString(w, swatchCardSVG(doc.Entries, opts.CoverageBars))
+	return err
+}
+
+// renderSwatchCard draws the swatch card as an image
+func renderSwatchCard(entries []Entry, coverageBars bool) *image.RGBA {
+	layout := newSwatchCardLayout(entries, coverageBars)
+	card := image.NewRGBA(image.Rectangle{Max: layout.size()})
+	fillRect(card, card.Bounds(), cardBackground)
+
+	for i, e := range entries {
+		swatch := layout.swatch(i)
+		fillRect(card, swatch.Inset(-1), cardBorder)
+		fillRect(card, swatch, e.Color)
+		drawText(card, labelOrigin(swatch), FormatHexColor(e.Color), labelColor(e.Color))
+
+		if coverageBars {
+			track, fill := layout.coverageBar(swatch, e.Weight)
+			fillRect(card, track, coverageTrack)
+			fillRect(card, fill, coverageFill)
+		}
+	}
+
+	return card
+}
+
+// swatchCardSVG builds the swatch card as an SVG document
+func swatchCardSVG(entries []Entry, coverageBars bool) string {
+	layout := newSwatchCardLayout(entries, coverageBars)
+	size := layout.size()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		size.X, size.Y, size.X, size.Y)
+	fmt.Fprintf(&sb, "  <rect width=\"%d\" height=\"%d\" fill=\"%v\"/>\n", size.X, size.Y, FormatHexColor(cardBackground))
+
+	for i, e := range entries {
+		swatch := layout.swatch(i)
+		hex := FormatHexColor(e.Color)
+		label := labelOrigin(swatch)
+
+		fmt.Fprintf(&sb, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%v\" stroke=\"%v\"/>\n",
+			swatch.Min.X, swatch.Min.Y, swatch.Dx(), swatch.Dy(), hex, FormatHexColor(cardBorder))
+		fmt.Fprintf(&sb, "  <text x=\"%d\" y=\"%d\" font-family=\"monospace\" font-size=\"%d\" fill=\"%v\">%v</text>\n",
+			label.X, label.Y+glyphHeight*labelScale, glyphHeight*labelScale, FormatHexColor(labelColor(e.Color)), hex)
+
+		if coverageBars {
+			track, fill := layout.coverageBar(swatch, e.Weight)
+			fmt.Fprintf(&sb, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%v\"/>\n",
+				track.Min.X, track.Min.Y, track.Dx(), track.Dy(), FormatHexColor(coverageTrack))
+			fmt.Fprintf(&sb, "  <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%v\"/>\n",
+				fill.Min.X, fill.Min.Y, fill.Dx(), fill.Dy(), FormatHexColor(coverageFill))
+		}
+	}
+
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// drawText draws text with the bitmap font, unknown glyphs are left blank
+func drawText(img draw.Image, origin image.Point, text string, c color.Color) {
+	for i, r := range text {
+		glyph, ok := bitmapFont[r]
+		if !ok {
+			continue
+		}
+		glyphX := origin.X + i*glyphAdvance*labelScale
+		for row, bits := range glyph {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				x, y := glyphX+col*labelScale, origin.Y+row*labelScale
+				fillRect(img, image.Rect(x, y, x+labelScale, y+labelScale), c)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package parsepalette
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testCardEntries = []Entry{
+	{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, Weight: 0.5},
+	{Color: color.RGBA{0xf3, 0x8b, 0xa8, 0xff}, Weight: 0.25},
+	{Color: color.RGBA{0xa6, 0xe3, 0xa1, 0xff}, Weight: 0.125},
+	{Color: color.RGBA{0xff, 0xff, 0xff, 0xff}, Weight: 0.125},
+}
+
+func Test_SaveSwatchCardPNG(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "card.png")
+
+	if err := SaveDocument(outputPath, &Document{Entries: testCardEntries}, SaveOptions{CoverageBars: true}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	file, err := os.Open(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	defer file.Close()
+	card, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	layout := newSwatchCardLayout(testCardEntries, true)
+	if card.Bounds().Size() != layout.size() {
+		t.Fatalf("Expected card size %v, got %v", layout.size(), card.Bounds().Size())
+	}
+
+	for i, e := range testCardEntries {
+		swatch := layout.swatch(i)
+		if got := color.RGBAModel.Convert(card.At(swatch.Max.X-2, swatch.Min.Y+2)); got != e.Color {
+			t.Errorf("Expected swatch %v to be %v, got %v", i, e.Color, got)
+		}
+
+		// the first pixel of the '#' glyph's top row is blank, the second is set
+		label := labelOrigin(swatch)
+		if got := color.RGBAModel.Convert(card.At(label.X+labelScale, label.Y)); got != labelColor(e.Color) {
+			t.Errorf("Expected label of swatch %v to be %v, got %v", i, labelColor(e.Color), got)
+		}
+
+		track, fill := layout.coverageBar(swatch, e.Weight)
+		if got := color.RGBAModel.Convert(card.At(fill.Min.X, fill.Min.Y)); got != coverageFill {
+			t.Errorf("Expected coverage bar of swatch %v to start filled, got %v", i, got)
+		}
+		if got := color.RGBAModel.Convert(card.At(track.Max.X-1, track.Min.Y)); i > 0 && got != coverageTrack {
+			t.Errorf("Expected coverage bar of swatch %v to end unfilled, got %v", i, got)
+		}
+	}
+}
+
+func Test_SaveSwatchCardSVG(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "card.svg")
+
+	if err := SaveDocument(outputPath, &Document{Entries: testCardEntries}, SaveOptions{}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	svg := string(content)
+
+	if !strings.HasPrefix(svg, "<svg ") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Errorf("Expected an svg document, got %q", svg)
+	}
+	for _, e := range testCardEntries {
+		hex := FormatHexColor(e.Color)
+		if !strings.Contains(svg, "fill=\""+hex+"\"") || !strings.Contains(svg, ">"+hex+"</text>") {
+			t.Errorf("Expected svg to contain swatch and label for %v", hex)
+		}
+	}
+}
+
+func Test_SaveSwatchCardInvalidExtension(t *testing.T) {
+	for _, fileName := range []string{"card.gif", "palette.jpg", "palette.JPEG"} {
+		outputPath := filepath.Join(t.TempDir(), fileName)
+
+		if err := ValidateOutputPath(outputPath); err == nil {
+			t.Errorf("Expected %v to be refused before saving, got no error", fileName)
+		}
+		if err := SaveDocument(outputPath, &Document{Entries: testCardEntries}, SaveOptions{}); err == nil {
+			t.Errorf("Expected error for %v, got none", fileName)
+		}
+		if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+			t.Errorf("Expected no file for %v, got %v", fileName, err)
+		}
+	}
+}
+
+func Test_DrawText(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, glyphAdvance*labelScale, glyphHeight*labelScale))
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	drawText(img, image.Point{}, "1", white)
+
+	// the top row of '1' is 00100
+	for col := 0; col < glyphWidth; col++ {
+		expected := color.RGBA{}
+		if col == 2 {
+			expected = white
+		}
+		if got := img.RGBAAt(col*labelScale, 0); got != expected {
+			t.Errorf("Expected pixel %v of glyph row to be %v, got %v", col, expected, got)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package parsepalette
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+)
+
+// tonalShades are the shade steps of a tonal scale with the OKLCh lightness of
+// each step, modelled on the default Tailwind color scales
+var tonalShades = []struct {
+	step      int
+	lightness float64
+}{
+	{50, 0.971}, {100, 0.936}, {200, 0.885}, {300, 0.808}, {400, 0.704}, {500, 0.637},
+	{600, 0.577}, {700, 0.505}, {800, 0.444}, {900, 0.396}, {950, 0.258},
+}
+
+// tonalShade is one step of a tonal scale
+type tonalShade struct {
+	step  int
+	color color.RGBA
+}
+
+// tonalScale builds the 50-950 scale of a color by varying its lightness in
+// OKLCh while keeping its hue and chroma, reducing chroma where the shade
+// would fall out of gamut. The color itself replaces the step with the
+// closest lightness.
+func tonalScale(c color.RGBA) []tonalShade {
+	base := colorspace.ToOKLCh(c)
+
+	closest := 0
+	for i, shade := range tonalShades {
+		if math.Abs(shade.lightness-base.L) < math.Abs(tonalShades[closest].lightness-base.L) {
+			closest = i
+		}
+	}
+
+	scale := make([]tonalShade, 0, len(tonalShades))
+	for i, shade := range tonalShades {
+		shadeColor := c
+		if i != closest {
+			shadeColor = colorspace.OKLCh{L: shade.lightness, C: base.C, H: base.H}.ToRGBA()
+		}
+		scale = append(scale, tonalShade{step: shade.step, color: shadeColor})
+	}
+
+	return scale
+}
+
+// tokenName names an entry in design token and Tailwind output after its role,
+// or after its index in the palette if it has none
+func tokenName(i int, e Entry) string {
+	if e.Role != "" {
+		return e.Role
+	}
+	return fmt.Sprintf("color-%d", i)
+}
+
+// writeDesignTokens writes the entries as a W3C Design Tokens group of color
+// tokens, each color a group with its 'base' value and its 50-950 scale
+func writeDesignTokens(w io.Writer, doc *Document, _ SaveOptions) error {
+	var sb strings.Builder
+	sb.WriteString("{\n  \"color\": {\n    \"$type\": \"color\"")
+
+	for i, e := range doc.Entries {
+		fmt.Fprintf(&sb, ",\n    %v: {\n", jsonString(tokenName(i, e)))
+		fmt.Fprintf(&sb, "      \"base\": { \"$value\": %v }", jsonString(FormatHexColor(e.Color)))
+		for _, shade := range tonalScale(e.Color) {
+			fmt.Fprintf(&sb, ",\n      \"%d\": { \"$value\": %v }", shade.step, jsonString(FormatHexColor(shade.color)))
+		}
+		sb.WriteString("\n    }")
+	}
+
+	sb.WriteString("\n  }\n}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeTailwind writes the entries as a Tailwind config extending the theme
+// colors, each color with its DEFAULT value and its 50-950 scale
+func writeTailwind(w io.Writer, doc *Document, _ SaveOptions) error {
+	var sb strings.Builder
+	sb.WriteString("/** @type {import('tailwindcss').Config} */\n")
+	sb.WriteString("module.exports = {\n  theme: {\n    extend: {\n      colors: {\n")
+
+	for i, e := range doc.Entries {
+		fmt.Fprintf(&sb, "        '%v': {\n", tokenName(i, e))
+		fmt.Fprintf(&sb, "          DEFAULT: '%v',\n", FormatHexColor(e.Color))
+		for _, shade := range tonalScale(e.Color) {
+			fmt.Fprintf(&sb, "          %d: '%v',\n", shade.step, FormatHexColor(shade.color))
+		}
+		sb.WriteString("        },\n")
+	}
+
+	sb.WriteString("      },\n    },\n  },\n}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func jsonString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
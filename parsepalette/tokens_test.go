@@ -0,0 +1,97 @@
+package parsepalette
+
+import (
+	"encoding/json"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+)
+
+func Test_TonalScale(t *testing.T) {
+	base := color.RGBA{0x1e, 0x66, 0xf5, 0xff}
+	scale := tonalScale(base)
+
+	if len(scale) != len(tonalShades) {
+		t.Fatalf("Expected %v shades, got %v", len(tonalShades), len(scale))
+	}
+
+	containsBase := false
+	for i, shade := range scale {
+		if shade.color == base {
+			containsBase = true
+		}
+		if i > 0 && colorspace.ToOKLab(shade.color).L >= colorspace.ToOKLab(scale[i-1].color).L {
+			t.Errorf("Expected shade %v to be darker than shade %v", shade.step, scale[i-1].step)
+		}
+	}
+	if !containsBase {
+		t.Errorf("Expected scale %v to contain the base color %v", scale, base)
+	}
+}
+
+func Test_SaveDocumentDesignTokens(t *testing.T) {
+	entries := []Entry{
+		{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, Role: "background"},
+		{Color: color.RGBA{0xf3, 0x8b, 0xa8, 0xff}},
+	}
+	outputPath := filepath.Join(t.TempDir(), "palette.tokens.json")
+
+	if err := SaveDocument(outputPath, &Document{Entries: entries}, SaveOptions{}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	var tokens struct {
+		Color map[string]json.RawMessage `json:"color"`
+	}
+	if err := json.Unmarshal(content, &tokens); err != nil {
+		t.Fatalf("Expected valid JSON, got error: %v", err)
+	}
+	if string(tokens.Color["$type"]) != `"color"` {
+		t.Errorf("Expected color group $type to be \"color\", got %s", tokens.Color["$type"])
+	}
+
+	for _, name := range []string{"background", "color-1"} {
+		var group map[string]struct {
+			Value string `json:"$value"`
+		}
+		if err := json.Unmarshal(tokens.Color[name], &group); err != nil {
+			t.Fatalf("Expected token group %v, got error: %v", name, err)
+		}
+		if len(group) != len(tonalShades)+1 {
+			t.Errorf("Expected %v tokens in group %v, got %v", len(tonalShades)+1, name, len(group))
+		}
+	}
+	if !strings.Contains(string(content), `"base": { "$value": "#1E1E2E" }`) {
+		t.Errorf("Expected base token of background, got %s", content)
+	}
+}
+
+func Test_SaveDocumentTailwind(t *testing.T) {
+	entries := []Entry{
+		{Color: color.RGBA{0xea, 0x76, 0xcb, 0xff}, Role: "brand"},
+	}
+	outputPath := filepath.Join(t.TempDir(), "tailwind.config.js")
+
+	if err := SaveDocument(outputPath, &Document{Entries: entries}, SaveOptions{}); err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Expected no error, got error: %v", err)
+	}
+
+	config := string(content)
+	for _, expected := range []string{"module.exports = {", "colors: {", "'brand': {", "DEFAULT: '#EA76CB',", "50: '#", "950: '#"} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("Expected Tailwind config to contain %q, got %s", expected, config)
+		}
+	}
+}
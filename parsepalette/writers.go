@@ -0,0 +1,192 @@
+package parsepalette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VannRR/color-schemorator/colorspace"
+)
+
+// SaveOptions are the settings of the palette writers, each writer uses the
+// options that apply to its format.
+type SaveOptions struct {
+	// CoverageBars draws a bar below every swatch of a swatch card
+	CoverageBars bool
+	// GoPackage is the package name of Go source output
+	GoPackage string
+}
+
+// paletteWriter writes a palette document to w in one file format
+type paletteWriter func(w io.Writer, doc *Document, opts SaveOptions) error
+
+// paletteWriters maps output file extensions to their writer, any other
+// extension but an image extension is written as a text palette
+var paletteWriters = map[string]paletteWriter{
+	".css":         writeCSS,
+	".scss":        writeSCSS,
+	".less":        writeLess,
+	".png":         writeSwatchCardPNG,
+	".svg":         writeSwatchCardSVG,
+	".tokens":      writeDesignTokens,
+	".tokens.json": writeDesignTokens,
+	".js":          writeTailwind,
+	".json":        writeJSON,
+	".go":          writeGoSource,
+}
+
+// imageExtensions are image formats without a palette writer, writing a text
+// palette to a file named like an image would only produce a broken image
+var imageExtensions = map[string]struct{}{
+	".bmp":  {},
+	".gif":  {},
+	".jpeg": {},
+	".jpg":  {},
+	".tif":  {},
+	".tiff": {},
+	".webp": {},
+}
+
+// writerFor returns the writer of the longest extension the path ends with,
+// so that 'palette.tokens.json' is not taken for a plain '.json' file
+func writerFor(paletteOutputPath string) (paletteWriter, error) {
+	lowerPath := strings.ToLower(filepath.Base(paletteOutputPath))
+	longest := ""
+	for ext := range paletteWriters {
+		if strings.HasSuffix(lowerPath, ext) && len(ext) > len(longest) {
+			longest = ext
+		}
+	}
+	if longest != "" {
+		return paletteWriters[longest], nil
+	}
+
+	ext := filepath.Ext(lowerPath)
+	if _, isImage := imageExtensions[ext]; isImage {
+		return nil, fmt.Errorf("cannot write a palette as %v image, use '.png' or '.svg' for a swatch card", ext)
+	}
+	return writeText, nil
+}
+
+// ValidateOutputPath checks that a palette can be saved to the path, so that
+// an unsupported extension fails before the palette is made.
+func ValidateOutputPath(paletteOutputPath string) error {
+	_, err := writerFor(paletteOutputPath)
+	return err
+}
+
+// SaveDocument saves a palette document in the format selected by the output
+// file extension: a '.json' palette with all metadata, '.css', '.scss' and
+// '.less' variables, '.png' and '.svg' swatch cards, '.tokens' or '.tokens.json'
+// W3C design tokens, a '.js' Tailwind config, a '.go' color.Palette variable,
+// or else a text palette of hex colors, one color per line. Other image
+// extensions are an error. The file is only written once the whole palette is
+// rendered, so a failing writer leaves an existing file untouched.
+func SaveDocument(paletteOutputPath string, doc *Document, opts SaveOptions) error {
+	write, err := writerFor(paletteOutputPath)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, doc, opts); err != nil {
+		return fmt.Errorf("failed to write palette to file: %w", err)
+	}
+
+	outputFile, err := os.Create(paletteOutputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create palette file: %w", err)
+	}
+	if _, err := outputFile.Write(buf.Bytes()); err != nil {
+		outputFile.Close()
+		return fmt.Errorf("failed to write palette to file: %w", err)
+	}
+	if err := outputFile.Close(); err != nil {
+		return fmt.Errorf("failed to write palette to file: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateRole checks that a role name can be used in variable names, it must
+// start with a letter followed by letters, digits, '-' or '_'.
+func ValidateRole(role string) error {
+	for i, r := range role {
+		isLetter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isOther := (r >= '0' && r <= '9') || r == '-' || r == '_'
+		if !isLetter && (i == 0 || !isOther) {
+			return fmt.Errorf("invalid role name '%v'", truncateString(role, 30))
+		}
+	}
+	if role == "" {
+		return fmt.Errorf("role name cannot be empty")
+	}
+	return nil
+}
+
+// writeText writes the entries as hex colors, one per line. Entries with a
+// weight get a '//' comment with the coverage, rgb() and hsl() values of the
+// color, which keeps the file a valid input for ParsePalette.
+func writeText(w io.Writer, doc *Document, _ SaveOptions) error {
+	lines := make([]string, 0, len(doc.Entries))
+	for _, e := range doc.Entries {
+		line := FormatHexColor(e.Color)
+		if e.Weight > 0 {
+			hsl := colorspace.ToHSL(e.Color)
+			line = fmt.Sprintf("%v // %5.1f%% rgb(%d, %d, %d) hsl(%.0f, %.0f%%, %.0f%%)",
+				line, e.Weight*100,
+				e.Color.R, e.Color.G, e.Color.B,
+				hsl.H, hsl.S*100, hsl.L*100)
+		}
+		lines = append(lines, line)
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// writeCSS writes the entries as custom properties of a :root block
+func writeCSS(w io.Writer, doc *Document, _ SaveOptions) error {
+	var sb strings.Builder
+	sb.WriteString(":root {\n")
+	for i, e := range doc.Entries {
+		fmt.Fprintf(&sb, "  --%v: %v;\n", variableName(i, e), FormatHexColor(e.Color))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// writeSCSS writes the entries as SCSS variables
+func writeSCSS(w io.Writer, doc *Document, _ SaveOptions) error {
+	return writeVariables(w, "$", doc.Entries)
+}
+
+// writeLess writes the entries as Less variables
+func writeLess(w io.Writer, doc *Document, _ SaveOptions) error {
+	return writeVariables(w, "@", doc.Entries)
+}
+
+// writeVariables writes one 'name: value;' variable declaration per entry
+func writeVariables(w io.Writer, sigil string, entries []Entry) error {
+	var sb strings.Builder
+	for i, e := range entries {
+		fmt.Fprintf(&sb, "%v%v: %v;\n", sigil, variableName(i, e), FormatHexColor(e.Color))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// variableName names the variable of an entry after its role, or after its
+// index in the palette if it has none
+func variableName(i int, e Entry) string {
+	if e.Role != "" {
+		return "color-" + e.Role
+	}
+	return fmt.Sprintf("color-%d", i)
+}
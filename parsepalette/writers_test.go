@@ -0,0 +1,74 @@
+package parsepalette
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_SaveDocumentVariables(t *testing.T) {
+	entries := []Entry{
+		{Color: color.RGBA{0x1e, 0x1e, 0x2e, 0xff}, Role: "background"},
+		{Color: color.RGBA{0xcd, 0xd6, 0xf4, 0xff}, Role: "foreground"},
+		{Color: color.RGBA{0xf3, 0x8b, 0xa8, 0xff}},
+	}
+
+	tests := []struct {
+		fileName string
+		expected string
+	}{
+		{"palette.css", ":root {\n" +
+			"  --color-background: #1E1E2E;\n" +
+			"  --color-foreground: #CDD6F4;\n" +
+			"  --color-2: #F38BA8;\n" +
+			"}\n"},
+		{"palette.scss", "$color-background: #1E1E2E;\n" +
+			"$color-foreground: #CDD6F4;\n" +
+			"$color-2: #F38BA8;\n"},
+		{"palette.less", "@color-background: #1E1E2E;\n" +
+			"@color-foreground: #CDD6F4;\n" +
+			"@color-2: #F38BA8;\n"},
+		{"palette.txt", "#1E1E2E\n#CDD6F4\n#F38BA8"},
+	}
+
+	for _, tt := range tests {
+		outputPath := filepath.Join(t.TempDir(), tt.fileName)
+		if err := SaveDocument(outputPath, &Document{Entries: entries}, SaveOptions{}); err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+
+		content, err := os.ReadFile(outputPath)
+		if err != nil {
+			t.Fatalf("Expected no error, got error: %v", err)
+		}
+		if string(content) != tt.expected {
+			t.Errorf("Expected %v to contain %q, got %q", tt.fileName, tt.expected, content)
+		}
+	}
+}
+
+func Test_ValidateRole(t *testing.T) {
+	tests := []struct {
+		role    string
+		isError bool
+	}{
+		{"background", false},
+		{"accent-2", false},
+		{"surface_0", false},
+		{"", true},
+		{"2nd", true},
+		{"-accent", true},
+		{"bg color", true},
+		{"bg;}", true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateRole(tt.role)
+		if err != nil && !tt.isError {
+			t.Errorf("Expected no error for role %q, but got: %v", tt.role, err)
+		} else if err == nil && tt.isError {
+			t.Errorf("Expected error for role %q, but got none", tt.role)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 var validExtensions = map[string]struct{}{
@@ -33,3 +34,29 @@ func ValidateFileSize(file *os.File, name string, maxMB int64) error {
 
 	return nil
 }
+
+// ListImageFiles returns the paths of the files with a valid image extension
+// directly inside a directory, sorted by name.
+func ListImageFiles(dirPath string) ([]string, error) {
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range dirEntries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, valid := validExtensions[filepath.Ext(entry.Name())]; valid {
+			paths = append(paths, filepath.Join(dirPath, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no images found in directory %v", dirPath)
+	}
+
+	return paths, nil
+}